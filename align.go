@@ -0,0 +1,85 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// ellipsis replaces the end of the prefixes longer than the prefix column
+const ellipsis = "…"
+
+// prefixColumnWidth returns the width of the prefix column, growing it to fit
+// the prefix if AutoPrefixWidth is set. It's zero if the prefixes are not
+// aligned.
+func (f *TextFormatter) prefixColumnWidth(prefix string) int {
+	if !f.AutoPrefixWidth {
+		return f.PrefixWidth
+	}
+
+	width := int32(utf8.RuneCountInString(prefix))
+	if f.PrefixWidth > 0 && width > int32(f.PrefixWidth) {
+		width = int32(f.PrefixWidth)
+	}
+	for {
+		current := atomic.LoadInt32(&f.prefixWidth)
+		if width <= current || atomic.CompareAndSwapInt32(&f.prefixWidth, current, width) {
+			break
+		}
+	}
+	return int(atomic.LoadInt32(&f.prefixWidth))
+}
+
+// fitPrefix truncates the prefix to the column width, ending it with an
+// ellipsis, and returns the spaces to fill the column
+func fitPrefix(prefix string, width int) (string, string) {
+	if width <= 0 {
+		return prefix, ""
+	}
+	n := utf8.RuneCountInString(prefix)
+	if n <= width {
+		return prefix, strings.Repeat(" ", width-n)
+	}
+	runes := []rune(prefix)
+	return string(runes[:width-1]) + ellipsis, ""
+}
+
+// fieldsPadding returns the spaces to print the first field key at the
+// FieldsColumn, or nothing if the fields do not fit in the terminal. The line
+// is the text printed before the fields and the fields are the text of the
+// fields, starting with a space.
+func (f *TextFormatter) fieldsPadding(w io.Writer, line, fields []byte) string {
+	if f.FieldsColumn <= 0 || len(fields) == 0 {
+		return ""
+	}
+	if i := bytes.LastIndexByte(line, '\n'); i >= 0 {
+		line = line[i+1:]
+	}
+	padding := f.FieldsColumn - textWidth(string(line)) - 1
+	if padding <= 0 {
+		return ""
+	}
+	if width := terminalWidth(w); width > 0 && f.FieldsColumn+textWidth(string(fields))-1 > width {
+		return ""
+	}
+	return strings.Repeat(" ", padding)
+}
+
+// terminalWidth returns the width of the terminal or zero if the output is not
+// a terminal
+func terminalWidth(w io.Writer) int {
+	file, ok := w.(*os.File)
+	if !ok || !checkIfTerminal(w) {
+		return 0
+	}
+	width, _, err := terminal.GetSize(int(file.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
@@ -0,0 +1,86 @@
+package log_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/spf13/viper"
+)
+
+func TestPrefixWidth(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.PrefixWidthKey, 6)
+	v.Set(log.RightAlignLevelKey, true)
+	l := newLogger(&b, v)
+
+	l.Prefix("db").Info("Connected")
+	l.Prefix("scheduler").Warn("Late")
+	l.WithField("id", 1).Error("Failed")
+	expectedLogMessage := " INFO db:     Connected\n" +
+		" WARN sched…: Late\n" +
+		"ERROR         Failed id=1\n"
+	actualLogMessage := b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+}
+
+func TestAutoPrefixWidth(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.AutoPrefixWidthKey, true)
+	v.Set(log.PrefixWidthKey, 8)
+	l := newLogger(&b, v)
+
+	l.Prefix("db").Info("One")
+	l.Prefix("api").Info("Two")
+	l.Prefix("db").Info("Three")
+	l.Prefix("scheduler").Info("Four")
+	l.Prefix("db").Info("Five")
+	expectedLogMessage := "INFO  db: One\n" +
+		"INFO  api: Two\n" +
+		"INFO  db:  Three\n" +
+		"INFO  schedul…: Four\n" +
+		"INFO  db:       Five\n"
+	actualLogMessage := b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+}
+
+func TestFieldsColumn(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.FieldsColumnKey, 24)
+	l := newLogger(&b, v)
+
+	l.Prefix("db").WithField("rows", 1).Info("Query")
+	l.Prefix("db").WithField("rows", 2).Info("A long message after the column")
+	l.Prefix("db").Info("No fields")
+	expectedLogMessage := "INFO  db: Query         rows=1\n" +
+		"INFO  db: A long message after the column rows=2\n" +
+		"INFO  db: No fields\n"
+	actualLogMessage := b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+}
+
+func TestValidateAlignment(t *testing.T) {
+	v := viper.New()
+	v.Set(log.PrefixWidthKey, -1)
+	if err := log.Validate(v); err == nil {
+		t.Errorf("Expected an error for a negative %s", log.PrefixWidthKey)
+	}
+}
@@ -0,0 +1,89 @@
+package log
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CallerField is the key of the caller in the structured formats
+const CallerField = "caller"
+
+// callerSkipPackages are the packages with the frames between the user code
+// and the logrus hooks: logrus, this package and the standard library loggers
+// bridged by StdLog and SlogHandler
+var callerSkipPackages = []string{
+	reflect.TypeOf(logrus.Logger{}).PkgPath() + ".",
+	reflect.TypeOf(Logger{}).PkgPath() + ".",
+	"log.",
+	"log/slog.",
+}
+
+// maximumCallerDepth is the number of frames inspected to find the caller
+const maximumCallerDepth = 32
+
+// callerHook replaces the caller found by logrus, which is always one of the
+// wrapper methods of this package, with the first frame outside of it
+type callerHook struct {
+	logger *Logger
+}
+
+func (h *callerHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *callerHook) Fire(entry *logrus.Entry) error {
+	// logrus only sets the caller if ReportCaller is enabled
+	if entry.Caller == nil || !h.logger.entryEnabled(entry) {
+		return nil
+	}
+	if frame := caller(); frame != nil {
+		entry.Caller = frame
+	}
+	return nil
+}
+
+// caller returns the first frame that is not in a logging package
+func caller() *runtime.Frame {
+	pcs := make([]uintptr, maximumCallerDepth)
+	depth := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:depth])
+
+	for {
+		frame, more := frames.Next()
+		if !isLoggingFrame(frame.Function) {
+			return &frame
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+func isLoggingFrame(function string) bool {
+	for _, pkg := range callerSkipPackages {
+		if strings.HasPrefix(function, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// shortCaller returns the caller as a short "file.go:42"
+func shortCaller(frame *runtime.Frame) string {
+	return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+}
+
+// shortFunction returns the caller function name without the package path,
+// like "main.run"
+func shortFunction(frame *runtime.Frame) string {
+	function := frame.Function
+	if i := strings.LastIndex(function, "/"); i >= 0 {
+		function = function[i+1:]
+	}
+	return function
+}
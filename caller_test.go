@@ -0,0 +1,129 @@
+package log_test
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// line returns the line number of the caller
+func line() int {
+	_, _, n, _ := runtime.Caller(1)
+	return n
+}
+
+func TestCaller(t *testing.T) {
+	var expectedLogMessage string
+	var actualLogMessage string
+
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.CallerKey, true)
+	l := newLogger(&b, v)
+	l.SetPrefix("test")
+
+	n := line() + 1
+	l.Infof("Hello %s", "World")
+	expectedLogMessage = fmt.Sprintf("INFO  test: caller_test.go:%d Hello World\n", n)
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	n = line() + 1
+	l.Prefix("entry").Warn("Hello")
+	expectedLogMessage = fmt.Sprintf("WARN  entry: caller_test.go:%d Hello\n", n)
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	n = line() + 1
+	l.StdLog("std", logrus.InfoLevel).Print("Hello")
+	expectedLogMessage = fmt.Sprintf("INFO  std: caller_test.go:%d Hello\n", n)
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	n = line() + 1
+	slog.New(l.SlogHandler()).Info("Hello")
+	expectedLogMessage = fmt.Sprintf("INFO  test: caller_test.go:%d Hello\n", n)
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	l.Formatter.(*log.TextFormatter).CallerFunction = true
+	l.Info("Hello")
+	expectedLogMessage = "INFO  test: log_test.TestCaller Hello\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	l.SetReportCaller(false)
+	l.Info("Hello")
+	expectedLogMessage = "INFO  test: Hello\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+}
+
+func TestCallerFormats(t *testing.T) {
+	var expectedLogMessage string
+	var actualLogMessage string
+
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.CallerKey, true)
+	v.Set(log.FormatKey, log.JSONFormat)
+	l := newLogger(&b, v)
+
+	n := line() + 1
+	l.WithField("caller", "me").Info("Hello")
+	expectedLogMessage = fmt.Sprintf(`{"caller":"caller_test.go:%d","fields.caller":"me","level":"info","msg":"Hello"}`+"\n", n)
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	l.SetFormatter(&log.LogfmtFormatter{DisableTimestamp: true})
+	n = line() + 1
+	l.Prefix("db").Info("Hello")
+	expectedLogMessage = fmt.Sprintf("level=info prefix=db caller=caller_test.go:%d msg=Hello\n", n)
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	c := l.Copy()
+	n = line() + 1
+	c.Info("Hello")
+	expectedLogMessage = fmt.Sprintf("level=info caller=caller_test.go:%d msg=Hello\n", n)
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+}
@@ -0,0 +1,300 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/mgutz/ansi"
+)
+
+// ColorLevel is the number of colors supported by the terminal
+type ColorLevel int
+
+// ColorAuto detects the colors supported by the output, ColorNone prints no
+// colors, Color16 uses the 16 basic colors, Color256 the 256 colors palette
+// and ColorTrueColor the 24-bit colors. The theme colors not supported are
+// replaced by the nearest supported color.
+const (
+	ColorAuto ColorLevel = iota
+	ColorNone
+	Color16
+	Color256
+	ColorTrueColor
+)
+
+var colorLevelNames = map[ColorLevel]string{
+	ColorAuto:      "auto",
+	ColorNone:      "none",
+	Color16:        "16",
+	Color256:       "256",
+	ColorTrueColor: "truecolor",
+}
+
+func (c ColorLevel) String() string {
+	if name, ok := colorLevelNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("ColorLevel(%d)", int(c))
+}
+
+// ParseColorLevel takes a color level name, "auto", "none", "16", "256" or
+// "truecolor", and returns the color level
+func ParseColorLevel(name string) (ColorLevel, error) {
+	switch strings.ToLower(name) {
+	case "", "auto":
+		return ColorAuto, nil
+	case "none", "off", "0":
+		return ColorNone, nil
+	case "16", "8", "ansi":
+		return Color16, nil
+	case "256", "ansi256":
+		return Color256, nil
+	case "truecolor", "24bit":
+		return ColorTrueColor, nil
+	}
+	return ColorAuto, fmt.Errorf("not a valid color level: %q", name)
+}
+
+// DetectColorLevel returns the colors supported by the output following the
+// conventions of the environment variables NO_COLOR, CLICOLOR_FORCE,
+// CLICOLOR, TERM and COLORTERM. If the output is not a terminal there are no
+// colors unless CLICOLOR_FORCE is set.
+func DetectColorLevel(w io.Writer) ColorLevel {
+	return detectColorLevel(w, false)
+}
+
+// detectColorLevel returns the colors supported by the output. If force is
+// true there are at least 16 colors, even if the output is not a terminal.
+func detectColorLevel(w io.Writer, force bool) ColorLevel {
+	if !force {
+		if os.Getenv("NO_COLOR") != "" {
+			return ColorNone
+		}
+		if v := os.Getenv("CLICOLOR_FORCE"); v != "" && v != "0" {
+			force = true
+		}
+	}
+	if !force {
+		if !checkIfTerminal(w) || runtime.GOOS == "windows" {
+			return ColorNone
+		}
+		if os.Getenv("CLICOLOR") == "0" || os.Getenv("TERM") == "dumb" {
+			return ColorNone
+		}
+	}
+
+	colorTerm := strings.ToLower(os.Getenv("COLORTERM"))
+	term := strings.ToLower(os.Getenv("TERM"))
+	switch {
+	case colorTerm == "truecolor" || colorTerm == "24bit":
+		return ColorTrueColor
+	case strings.Contains(term, "256color"):
+		return Color256
+	default:
+		return Color16
+	}
+}
+
+// colorAttributes are the ANSI codes of the style attributes in the order
+// printed by github.com/mgutz/ansi
+var colorAttributes = []struct {
+	attr byte
+	code string
+}{
+	{'b', "1"},
+	{'d', "2"},
+	{'B', "5"},
+	{'u', "4"},
+	{'i', "7"},
+	{'s', "9"},
+}
+
+// colorCode returns the ANSI code of the style with the colors supported by
+// the color level. Like github.com/mgutz/ansi the style is
+// "foreground+attributes:background+attributes" where the colors are names,
+// 256 colors palette indexes or "#rrggbb".
+func colorCode(level ColorLevel, style string) string {
+	if level == ColorNone || style == "" || style == "off" {
+		return ""
+	}
+	if style == "reset" {
+		return ansi.Reset
+	}
+
+	fg, bg := style, ""
+	if i := strings.Index(style, ":"); i >= 0 {
+		fg, bg = style[:i], style[i+1:]
+	}
+	fgColor, fgAttrs := splitStyle(fg)
+	bgColor, bgAttrs := splitStyle(bg)
+
+	codes := []string{"0"}
+	for _, a := range colorAttributes {
+		if strings.IndexByte(fgAttrs, a.attr) >= 0 {
+			codes = append(codes, a.code)
+		}
+	}
+	if code := colorCodeOf(level, fgColor, strings.Contains(fgAttrs, "h"), false); code != "" {
+		codes = append(codes, code)
+	}
+	if code := colorCodeOf(level, bgColor, strings.Contains(bgAttrs, "h"), true); code != "" {
+		codes = append(codes, code)
+	}
+	return "\033[" + strings.Join(codes, ";") + "m"
+}
+
+func splitStyle(style string) (color, attrs string) {
+	if i := strings.Index(style, "+"); i >= 0 {
+		return style[:i], style[i+1:]
+	}
+	return style, ""
+}
+
+// colorCodeOf returns the ANSI code of a foreground or background color
+func colorCodeOf(level ColorLevel, color string, high, background bool) string {
+	if color == "" {
+		return ""
+	}
+
+	base, base256 := 30, "38"
+	if background {
+		base, base256 = 40, "48"
+	}
+
+	if strings.HasPrefix(color, "#") {
+		r, g, b, err := parseHexColor(color)
+		if err != nil {
+			return ""
+		}
+		switch level {
+		case ColorTrueColor:
+			return fmt.Sprintf("%s;2;%d;%d;%d", base256, r, g, b)
+		case Color256:
+			return fmt.Sprintf("%s;5;%d", base256, nearest256(r, g, b))
+		default:
+			return basicColorCode(base, nearest16(r, g, b))
+		}
+	}
+
+	if n, err := strconv.Atoi(color); err == nil {
+		if level >= Color256 {
+			return fmt.Sprintf("%s;5;%d", base256, n)
+		}
+		if n >= 16 {
+			r, g, b := rgb256(n)
+			n = nearest16(r, g, b)
+		}
+		return basicColorCode(base, n)
+	}
+
+	n, ok := ansi.Colors[color]
+	if !ok {
+		return ""
+	}
+	if high {
+		base += 60
+	}
+	return strconv.Itoa(base + n)
+}
+
+// basicColorCode returns the code of one of the 16 basic colors, 8 to 15 are
+// the high intensity colors
+func basicColorCode(base, n int) string {
+	if n >= 8 {
+		return strconv.Itoa(base + 60 + n - 8)
+	}
+	return strconv.Itoa(base + n)
+}
+
+func parseHexColor(color string) (r, g, b int, err error) {
+	if len(color) != 7 {
+		return 0, 0, 0, fmt.Errorf("invalid color %q, it should be #rrggbb", color)
+	}
+	v, err := strconv.ParseUint(color[1:], 16, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid color %q, it should be #rrggbb", color)
+	}
+	return int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff), nil
+}
+
+// palette16 has the RGB of the 16 basic colors as printed by xterm
+var palette16 = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels are the values of every component in the 6x6x6 color cube of the
+// 256 colors palette
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// rgb256 returns the RGB of a color of the 256 colors palette
+func rgb256(n int) (r, g, b int) {
+	switch {
+	case n < 16:
+		c := palette16[n]
+		return c[0], c[1], c[2]
+	case n < 232:
+		n -= 16
+		return cubeLevels[n/36], cubeLevels[n/6%6], cubeLevels[n%6]
+	default:
+		gray := 8 + (n-232)*10
+		return gray, gray, gray
+	}
+}
+
+// nearest16 returns the basic color closer to the RGB color
+func nearest16(r, g, b int) int {
+	nearest, min := 0, -1
+	for i, c := range palette16 {
+		if d := colorDistance(r, g, b, c[0], c[1], c[2]); min < 0 || d < min {
+			nearest, min = i, d
+		}
+	}
+	return nearest
+}
+
+// nearest256 returns the color of the 256 colors palette closer to the RGB
+// color, from the color cube or the grays
+func nearest256(r, g, b int) int {
+	cube := func(v int) int {
+		nearest := 0
+		for i, l := range cubeLevels {
+			if abs(v-l) < abs(v-cubeLevels[nearest]) {
+				nearest = i
+			}
+		}
+		return nearest
+	}
+	ci := 16 + 36*cube(r) + 6*cube(g) + cube(b)
+
+	gi := 232 + ((r+g+b)/3-3)/10
+	if gi < 232 {
+		gi = 232
+	} else if gi > 255 {
+		gi = 255
+	}
+
+	cr, cg, cb := rgb256(ci)
+	gr, gg, gb := rgb256(gi)
+	if colorDistance(r, g, b, gr, gg, gb) < colorDistance(r, g, b, cr, cg, cb) {
+		return gi
+	}
+	return ci
+}
+
+func colorDistance(r1, g1, b1, r2, g2, b2 int) int {
+	return (r1-r2)*(r1-r2) + (g1-g2)*(g1-g2) + (b1-b2)*(b1-b2)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,108 @@
+package log_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/spf13/viper"
+)
+
+func TestDetectColorLevel(t *testing.T) {
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		expected log.ColorLevel
+	}{
+		{"not a terminal", map[string]string{}, log.ColorNone},
+		{"forced", map[string]string{"CLICOLOR_FORCE": "1"}, log.Color16},
+		{"forced 256", map[string]string{"CLICOLOR_FORCE": "1", "TERM": "xterm-256color"}, log.Color256},
+		{"forced truecolor", map[string]string{"CLICOLOR_FORCE": "1", "COLORTERM": "truecolor"}, log.ColorTrueColor},
+		{"not forced", map[string]string{"CLICOLOR_FORCE": "0"}, log.ColorNone},
+		{"no color", map[string]string{"CLICOLOR_FORCE": "1", "NO_COLOR": "1"}, log.ColorNone},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, name := range []string{"NO_COLOR", "CLICOLOR", "CLICOLOR_FORCE", "TERM", "COLORTERM"} {
+				t.Setenv(name, tc.env[name])
+			}
+			if actual := log.DetectColorLevel(&bytes.Buffer{}); actual != tc.expected {
+				t.Errorf("Expected color level %s, but got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestColorLevel(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("CLICOLOR_FORCE", "")
+
+	testCases := []struct {
+		level    string
+		color    string
+		expected string
+	}{
+		{"none", "#ff8700", "INFO  app: Hello\n"},
+		{"16", "#ff8700", "\x1b[0;33mINFO \x1b[0m app: Hello\n"},
+		{"16", "208", "\x1b[0;33mINFO \x1b[0m app: Hello\n"},
+		{"16", "red+b", "\x1b[0;1;31mINFO \x1b[0m app: Hello\n"},
+		{"256", "#ff8700", "\x1b[0;38;5;208mINFO \x1b[0m app: Hello\n"},
+		{"256", "#303030", "\x1b[0;38;5;236mINFO \x1b[0m app: Hello\n"},
+		{"truecolor", "#ff8700:#000000", "\x1b[0;38;2;255;135;0;48;2;0;0;0mINFO \x1b[0m app: Hello\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.level+" "+tc.color, func(t *testing.T) {
+			var b bytes.Buffer
+			v := viper.New()
+			v.Set(log.DisableTimestampKey, true)
+			v.Set(log.ColorLevelKey, tc.level)
+			v.Set(log.ColorsKey, map[string]string{"info": tc.color})
+			l := newLogger(&b, v)
+			l.SetPrefix("app")
+
+			l.Info("Hello")
+			if actual := b.String(); actual != tc.expected {
+				t.Errorf("Expected %q, but got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	t.Setenv("CLICOLOR_FORCE", "1")
+
+	var b bytes.Buffer
+	v := viper.New()
+	v.Set(log.DisableTimestampKey, true)
+	l := newLogger(&b, v)
+	l.SetPrefix("app")
+
+	l.Info("Hello")
+	expectedLogMessage := "INFO  app: Hello\n"
+	if actualLogMessage := b.String(); actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	// the configuration has precedence over the environment
+	l.Formatter.(*log.TextFormatter).ForceColors = true
+	l.Info("Hello")
+	expectedLogMessage = "\x1b[0;32mINFO \x1b[0m app: Hello\n"
+	if actualLogMessage := b.String(); actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+}
+
+func TestParseColorLevel(t *testing.T) {
+	for name, expected := range map[string]log.ColorLevel{"": log.ColorAuto, "auto": log.ColorAuto, "NONE": log.ColorNone, "256": log.Color256, "24bit": log.ColorTrueColor} {
+		if actual, err := log.ParseColorLevel(name); err != nil || actual != expected {
+			t.Errorf("Expected color level %s for %q, but got %s, %v", expected, name, actual, err)
+		}
+	}
+	if _, err := log.ParseColorLevel("millions"); err == nil {
+		t.Errorf("Expected an error for an invalid color level")
+	}
+}
@@ -0,0 +1,442 @@
+package log
+
+import (
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// Config has all the settings to create a Logger. The mapstructure tags are
+// the viper variables, so it can also be loaded with viper.Unmarshal
+type Config struct {
+	// Output is the writer to send the logs to. If nil, the logs are sent to
+	// Filename or to the standard error if there is no filename
+	Output io.Writer `mapstructure:"log_output"`
+
+	// RawOutput makes the cli.Ui Output method print the raw messages to
+	// UIOutput, or the standard output if UIOutput is nil, instead of printing
+	// them as log entries
+	RawOutput bool      `mapstructure:"log_ui_raw"`
+	UIOutput  io.Writer `mapstructure:"log_ui_output"`
+
+	// Input is the reader to read the answers of Ask and AskSecret. If nil, the
+	// standard input is used
+	Input io.Reader `mapstructure:"log_input"`
+
+	// Filename is the file to send the logs to and its rotation settings
+	Filename   string `mapstructure:"log_filename"`
+	MaxSize    int    `mapstructure:"log_maxsize"`
+	MaxAge     int    `mapstructure:"log_maxage"`
+	MaxBackups int    `mapstructure:"log_maxbackups"`
+	Daily      bool   `mapstructure:"log_daily"`
+	Compress   bool   `mapstructure:"log_compress"`
+	Reopen     bool   `mapstructure:"log_reopen"`
+
+	// Level is the log level name, such as "debug" or "info", or "off" to not
+	// print any entry
+	Level string `mapstructure:"log_level"`
+
+	// Levels is the level name for some prefixes or glob patterns, overriding
+	// Level for the entries with those prefixes
+	Levels map[string]string `mapstructure:"log_levels"`
+
+	// Format is the log format, one of "text", "json" or "logfmt"
+	Format string `mapstructure:"log_format"`
+
+	ForceColors      bool   `mapstructure:"log_color"`
+	DisableColors    bool   `mapstructure:"log_nocolor"`
+	DisableTimestamp bool   `mapstructure:"log_notimestamp"`
+	ShortTimestamp   bool   `mapstructure:"log_shorttimestamp"`
+	TimestampFormat  string `mapstructure:"log_formattimestamp"`
+	AbbreviatePrefix bool   `mapstructure:"log_abbreviate_prefix"`
+
+	// TimestampMode is how the text formatter prints the timestamp, one of
+	// "full", "short", "elapsed", "delta", "utc", "local" or "rfc3339nano"
+	TimestampMode string `mapstructure:"log_timestamp_mode"`
+
+	// ValueFormat is how the text formatter prints the structs, maps and slices
+	// in the fields, one of "default", "json", "verbose" or "expand", with up to
+	// MaxValueDepth nesting levels and MaxValueLength characters
+	ValueFormat    string `mapstructure:"log_value_format"`
+	MaxValueDepth  int    `mapstructure:"log_value_depth"`
+	MaxValueLength int    `mapstructure:"log_value_length"`
+
+	// Multiline is how the text formatter prints the messages and values with
+	// newlines, one of "raw", "indent", "header" or "escape"
+	Multiline string `mapstructure:"log_multiline"`
+
+	// Theme is the name of the color theme of the text formatter and Colors
+	// replaces some of its colors, such as "info" or "prefix", with
+	// mgutz/ansi styles
+	Theme  string            `mapstructure:"log_theme"`
+	Colors map[string]string `mapstructure:"log_colors"`
+
+	// ColorLevel is the colors supported by the terminal, one of "auto",
+	// "none", "16", "256" or "truecolor". If empty or "auto" they are detected
+	ColorLevel string `mapstructure:"log_color_level"`
+
+	// HashPrefixColors prints every prefix with a color of PrefixPalette taken
+	// from a hash of the prefix. PrefixColors has the color of some prefixes.
+	// Viper makes the keys lowercase, so PrefixColors from viper works only
+	// with lowercase prefixes
+	HashPrefixColors bool              `mapstructure:"log_prefix_color_hash"`
+	PrefixPalette    []string          `mapstructure:"log_prefix_palette"`
+	PrefixColors     map[string]string `mapstructure:"log_prefix_colors"`
+
+	// PrefixWidth, AutoPrefixWidth, RightAlignLevel and FieldsColumn align the
+	// prefixes, levels and fields of the text formatter in columns
+	PrefixWidth     int  `mapstructure:"log_prefix_width"`
+	AutoPrefixWidth bool `mapstructure:"log_prefix_auto_width"`
+	RightAlignLevel bool `mapstructure:"log_level_right_align"`
+	FieldsColumn    int  `mapstructure:"log_fields_column"`
+
+	// Prefix is the prefix of the entries printed by the logger
+	Prefix string `mapstructure:"prefix"`
+
+	// ReportCaller adds the file and line of the code printing the entry
+	ReportCaller bool `mapstructure:"log_caller"`
+
+	// StackTraceLevel is the level name, such as "error", of the least severe
+	// entries printed with a stack trace. If empty or "off" there are no stack
+	// traces
+	StackTraceLevel string `mapstructure:"log_stacktrace_level"`
+
+	// SniffLevels takes the level of the standard library logger messages from
+	// level words such as [ERROR] at the beginning of the message
+	SniffLevels bool `mapstructure:"log_sniff_levels"`
+}
+
+// DefaultConfig returns the settings used when the user do not set them
+func DefaultConfig() Config {
+	return Config{
+		Level:            defLevel.String(),
+		Format:           defFormat,
+		ForceColors:      defForceColors,
+		DisableColors:    defDisableColors,
+		DisableTimestamp: defDisableTimestamp,
+		ShortTimestamp:   defShortTimestamp,
+		TimestampFormat:  defTimestampFormat,
+		Prefix:           defPrefix,
+	}
+}
+
+// ConfigFromViper returns the settings defined in the viper instance, using the
+// defaults for the variables that are not set
+func ConfigFromViper(v *viper.Viper) Config {
+	c := DefaultConfig()
+
+	if out, ok := v.Get(OutputKey).(io.Writer); ok && v.IsSet(OutputKey) {
+		c.Output = out
+	}
+	if v.IsSet(RawOutputKey) {
+		c.RawOutput = v.GetBool(RawOutputKey)
+	}
+	if out, ok := v.Get(UIOutputKey).(io.Writer); ok && v.IsSet(UIOutputKey) {
+		c.UIOutput = out
+	}
+	if in, ok := v.Get(InputKey).(io.Reader); ok && v.IsSet(InputKey) {
+		c.Input = in
+	}
+	if v.IsSet(FilenameKey) {
+		c.Filename = v.GetString(FilenameKey)
+	}
+	if v.IsSet(MaxSizeKey) {
+		c.MaxSize = v.GetInt(MaxSizeKey)
+	}
+	if v.IsSet(MaxAgeKey) {
+		c.MaxAge = v.GetInt(MaxAgeKey)
+	}
+	if v.IsSet(MaxBackupsKey) {
+		c.MaxBackups = v.GetInt(MaxBackupsKey)
+	}
+	if v.IsSet(DailyKey) {
+		c.Daily = v.GetBool(DailyKey)
+	}
+	if v.IsSet(CompressKey) {
+		c.Compress = v.GetBool(CompressKey)
+	}
+	if v.IsSet(ReopenKey) {
+		c.Reopen = v.GetBool(ReopenKey)
+	}
+	if v.IsSet(LevelKey) {
+		c.Level = v.GetString(LevelKey)
+	}
+	if v.IsSet(LevelsKey) {
+		c.Levels = v.GetStringMapString(LevelsKey)
+	}
+	if v.IsSet(FormatKey) {
+		c.Format = v.GetString(FormatKey)
+	}
+	if v.IsSet(ForceColorsKey) {
+		c.ForceColors = v.GetBool(ForceColorsKey)
+	}
+	if v.IsSet(DisableColorsKey) {
+		c.DisableColors = v.GetBool(DisableColorsKey)
+	}
+	if v.IsSet(DisableTimestampKey) {
+		c.DisableTimestamp = v.GetBool(DisableTimestampKey)
+	}
+	if v.IsSet(ShortTimestampKey) {
+		c.ShortTimestamp = v.GetBool(ShortTimestampKey)
+	}
+	if v.IsSet(TimestampFormatKey) {
+		c.TimestampFormat = v.GetString(TimestampFormatKey)
+	}
+	if v.IsSet(AbbreviatePrefixKey) {
+		c.AbbreviatePrefix = v.GetBool(AbbreviatePrefixKey)
+	}
+	if v.IsSet(TimestampModeKey) {
+		c.TimestampMode = v.GetString(TimestampModeKey)
+	}
+	if v.IsSet(ValueFormatKey) {
+		c.ValueFormat = v.GetString(ValueFormatKey)
+	}
+	if v.IsSet(MaxValueDepthKey) {
+		c.MaxValueDepth = v.GetInt(MaxValueDepthKey)
+	}
+	if v.IsSet(MaxValueLengthKey) {
+		c.MaxValueLength = v.GetInt(MaxValueLengthKey)
+	}
+	if v.IsSet(MultilineKey) {
+		c.Multiline = v.GetString(MultilineKey)
+	}
+	if v.IsSet(ThemeKey) {
+		c.Theme = v.GetString(ThemeKey)
+	}
+	if v.IsSet(ColorsKey) {
+		c.Colors = v.GetStringMapString(ColorsKey)
+	}
+	if v.IsSet(ColorLevelKey) {
+		c.ColorLevel = v.GetString(ColorLevelKey)
+	}
+	if v.IsSet(HashPrefixColorsKey) {
+		c.HashPrefixColors = v.GetBool(HashPrefixColorsKey)
+	}
+	if v.IsSet(PrefixPaletteKey) {
+		c.PrefixPalette = v.GetStringSlice(PrefixPaletteKey)
+	}
+	if v.IsSet(PrefixColorsKey) {
+		c.PrefixColors = v.GetStringMapString(PrefixColorsKey)
+	}
+	if v.IsSet(PrefixWidthKey) {
+		c.PrefixWidth = v.GetInt(PrefixWidthKey)
+	}
+	if v.IsSet(AutoPrefixWidthKey) {
+		c.AutoPrefixWidth = v.GetBool(AutoPrefixWidthKey)
+	}
+	if v.IsSet(RightAlignLevelKey) {
+		c.RightAlignLevel = v.GetBool(RightAlignLevelKey)
+	}
+	if v.IsSet(FieldsColumnKey) {
+		c.FieldsColumn = v.GetInt(FieldsColumnKey)
+	}
+	if v.IsSet(PrefixField) {
+		c.Prefix = v.GetString(PrefixField)
+	}
+	if v.IsSet(CallerKey) {
+		c.ReportCaller = v.GetBool(CallerKey)
+	}
+	if v.IsSet(StackTraceLevelKey) {
+		c.StackTraceLevel = v.GetString(StackTraceLevelKey)
+	}
+	if v.IsSet(SniffLevelsKey) {
+		c.SniffLevels = v.GetBool(SniffLevelsKey)
+	}
+
+	return c
+}
+
+// ToViper sets every setting in the viper instance, so ConfigFromViper returns
+// the same settings
+func (c Config) ToViper(v *viper.Viper) {
+	if c.Output != nil {
+		v.Set(OutputKey, c.Output)
+	}
+	v.Set(RawOutputKey, c.RawOutput)
+	if c.UIOutput != nil {
+		v.Set(UIOutputKey, c.UIOutput)
+	}
+	if c.Input != nil {
+		v.Set(InputKey, c.Input)
+	}
+	if c.Filename != "" {
+		v.Set(FilenameKey, c.Filename)
+	}
+	v.Set(MaxSizeKey, c.MaxSize)
+	v.Set(MaxAgeKey, c.MaxAge)
+	v.Set(MaxBackupsKey, c.MaxBackups)
+	v.Set(DailyKey, c.Daily)
+	v.Set(CompressKey, c.Compress)
+	v.Set(ReopenKey, c.Reopen)
+	v.Set(LevelKey, c.Level)
+	if len(c.Levels) != 0 {
+		v.Set(LevelsKey, c.Levels)
+	}
+	v.Set(FormatKey, c.Format)
+	v.Set(ForceColorsKey, c.ForceColors)
+	v.Set(DisableColorsKey, c.DisableColors)
+	v.Set(DisableTimestampKey, c.DisableTimestamp)
+	v.Set(ShortTimestampKey, c.ShortTimestamp)
+	v.Set(TimestampFormatKey, c.TimestampFormat)
+	v.Set(AbbreviatePrefixKey, c.AbbreviatePrefix)
+	v.Set(TimestampModeKey, c.TimestampMode)
+	v.Set(ValueFormatKey, c.ValueFormat)
+	v.Set(MaxValueDepthKey, c.MaxValueDepth)
+	v.Set(MaxValueLengthKey, c.MaxValueLength)
+	v.Set(MultilineKey, c.Multiline)
+	if c.Theme != "" {
+		v.Set(ThemeKey, c.Theme)
+	}
+	if len(c.Colors) != 0 {
+		v.Set(ColorsKey, c.Colors)
+	}
+	if c.ColorLevel != "" {
+		v.Set(ColorLevelKey, c.ColorLevel)
+	}
+	v.Set(HashPrefixColorsKey, c.HashPrefixColors)
+	if len(c.PrefixPalette) != 0 {
+		v.Set(PrefixPaletteKey, c.PrefixPalette)
+	}
+	if len(c.PrefixColors) != 0 {
+		v.Set(PrefixColorsKey, c.PrefixColors)
+	}
+	v.Set(PrefixWidthKey, c.PrefixWidth)
+	v.Set(AutoPrefixWidthKey, c.AutoPrefixWidth)
+	v.Set(RightAlignLevelKey, c.RightAlignLevel)
+	v.Set(FieldsColumnKey, c.FieldsColumn)
+	v.Set(PrefixField, c.Prefix)
+	v.Set(CallerKey, c.ReportCaller)
+	if c.StackTraceLevel != "" {
+		v.Set(StackTraceLevelKey, c.StackTraceLevel)
+	}
+	v.Set(SniffLevelsKey, c.SniffLevels)
+}
+
+// NewFromConfig creates a new Logger with the given settings
+func NewFromConfig(c Config) *Logger {
+	logger := &Logger{
+		prefix:      c.Prefix,
+		sniffLevels: c.SniffLevels,
+		input:       c.Input,
+		levels:      newPrefixLevels(defLevel),
+	}
+	logger.Formatter = c.formatter()
+	withClock(logger.Formatter, nil)
+	logger.Out = os.Stderr
+	logger.Level = defLevel
+	logger.ReportCaller = c.ReportCaller
+	logger.stackLevel = OffLevel
+	if c.StackTraceLevel != "" {
+		if level, err := ParsePrefixLevel(c.StackTraceLevel); err == nil {
+			logger.stackLevel = level
+		}
+	}
+	logger.addHooks()
+
+	if c.RawOutput {
+		logger.uiOutput = c.UIOutput
+		if logger.uiOutput == nil {
+			logger.uiOutput = os.Stdout
+			logger.stdoutUI = true
+		}
+	}
+
+	if c.Output != nil {
+		logger.Out = c.Output
+	} else if c.Filename != "" {
+		out, err := c.rotatingFile()
+		if err == nil {
+			logger.Out = out
+			if c.Reopen {
+				out.reopenOnSignal()
+			}
+		} else {
+			logger.Errorf("Cannot create log file %s. %s", c.Filename, err)
+		}
+	}
+
+	if c.Level != "" {
+		logLevel, err := ParsePrefixLevel(c.Level)
+		if err == nil {
+			logger.levels.setBase(logLevel)
+		}
+	}
+	for prefix, name := range c.Levels {
+		logLevel, err := ParsePrefixLevel(name)
+		if err == nil {
+			logger.levels.set(prefix, logLevel)
+		}
+	}
+	logger.applyLevels()
+
+	return logger
+}
+
+// formatter creates the formatter defined by Format
+func (c Config) formatter() logrus.Formatter {
+	switch c.Format {
+	case JSONFormat:
+		return &JSONFormatter{
+			DisableTimestamp: c.DisableTimestamp,
+			TimestampFormat:  c.TimestampFormat,
+		}
+	case LogfmtFormat:
+		return &LogfmtFormatter{
+			DisableTimestamp: c.DisableTimestamp,
+			TimestampFormat:  c.TimestampFormat,
+		}
+	default:
+		var theme *Theme
+		if c.Theme != "" || len(c.Colors) != 0 {
+			theme, _ = NewTheme(c.Theme, c.Colors)
+		}
+		colorLevel, _ := ParseColorLevel(c.ColorLevel)
+		var prefixColors *PrefixColors
+		if c.HashPrefixColors || len(c.PrefixColors) != 0 {
+			prefixColors = &PrefixColors{
+				Hash:    c.HashPrefixColors,
+				Palette: c.PrefixPalette,
+				Colors:  c.PrefixColors,
+			}
+		}
+		return &TextFormatter{
+			ForceColors:      c.ForceColors,
+			DisableColors:    c.DisableColors,
+			DisableTimestamp: c.DisableTimestamp,
+			ShortTimestamp:   c.ShortTimestamp,
+			TimestampFormat:  c.TimestampFormat,
+			AbbreviatePrefix: c.AbbreviatePrefix,
+			Multiline:        c.Multiline,
+			Theme:            theme,
+			ColorLevel:       colorLevel,
+			PrefixColors:     prefixColors,
+			PrefixWidth:      c.PrefixWidth,
+			AutoPrefixWidth:  c.AutoPrefixWidth,
+			RightAlignLevel:  c.RightAlignLevel,
+			FieldsColumn:     c.FieldsColumn,
+			TimestampMode:    c.TimestampMode,
+			ValueFormat:      c.ValueFormat,
+			MaxValueDepth:    c.MaxValueDepth,
+			MaxValueLength:   c.MaxValueLength,
+		}
+	}
+}
+
+// rotatingFile opens the log file defined by Filename
+func (c Config) rotatingFile() (*RotatingFile, error) {
+	return sharedRotatingFile(c.rotatingFileSettings())
+}
+
+// rotatingFileSettings returns the log file defined by Filename, not opened
+func (c Config) rotatingFileSettings() *RotatingFile {
+	return &RotatingFile{
+		Filename:   c.Filename,
+		MaxSize:    c.MaxSize,
+		MaxAge:     c.MaxAge,
+		MaxBackups: c.MaxBackups,
+		Daily:      c.Daily,
+		Compress:   c.Compress,
+	}
+}
@@ -0,0 +1,93 @@
+package log_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func TestConfigRoundTrip(t *testing.T) {
+	var b bytes.Buffer
+	expected := log.Config{
+		Output:          &b,
+		Level:           "debug",
+		Format:          log.LogfmtFormat,
+		DisableColors:   true,
+		ShortTimestamp:  true,
+		TimestampFormat: "15:04",
+		MaxBackups:      3,
+		Prefix:          "test",
+	}
+
+	v := viper.New()
+	expected.ToViper(v)
+	actual := log.ConfigFromViper(v)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected '%+v', but got '%+v'", expected, actual)
+	}
+
+	var unmarshaled log.Config
+	if err := v.Unmarshal(&unmarshaled); err != nil {
+		t.Fatalf("Cannot unmarshal the config. %s", err)
+	}
+	if !reflect.DeepEqual(unmarshaled, expected) {
+		t.Errorf("Expected '%+v', but got '%+v'", expected, unmarshaled)
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	var expectedLogMessage string
+	var actualLogMessage string
+
+	var b bytes.Buffer
+	c := log.DefaultConfig()
+	c.Output = &b
+	c.DisableTimestamp = true
+	c.DisableColors = true
+	c.Prefix = "test"
+
+	l := log.NewFromConfig(c)
+	if l.GetLevel() != logrus.InfoLevel {
+		t.Errorf("Expected default level info, but got %s", l.GetLevel())
+	}
+
+	l.Debugf("Debug")
+	l.Infof("Information")
+	expectedLogMessage = "INFO  test: Information\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+}
+
+func TestNewDefault(t *testing.T) {
+	defer viper.Reset()
+
+	viper.Set(log.DisableColorsKey, true)
+	viper.Set(log.DisableTimestampKey, true)
+	viper.Set(log.ShortTimestampKey, true)
+
+	l := log.NewDefault()
+	formatter, ok := l.Formatter.(*log.TextFormatter)
+	if !ok {
+		t.Fatalf("Expected a TextFormatter, but got %T", l.Formatter)
+	}
+	expected := log.TextFormatter{
+		DisableColors:    true,
+		DisableTimestamp: true,
+		ShortTimestamp:   true,
+	}
+	// the formatter also has the clock of the logger, only the settings are
+	// compared
+	actual, want := reflect.ValueOf(*formatter), reflect.ValueOf(expected)
+	for i := 0; i < want.NumField(); i++ {
+		field := want.Type().Field(i)
+		if field.IsExported() && actual.Field(i).Interface() != want.Field(i).Interface() {
+			t.Errorf("Expected %s to be '%v', but got '%v'", field.Name, want.Field(i), actual.Field(i))
+		}
+	}
+}
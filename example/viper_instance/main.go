@@ -15,6 +15,8 @@ func main() {
 	v1.Set(log.LevelKey, "debug")
 	v1.Set(log.PrefixField, "main")
 
+	log.Configure(v1)
+
 	logMain := log.Prefix("main")
 	logMain.Printf("Testing main with some parameters. %d %s %d = %d", 10, "+", 10, 10+10)
 
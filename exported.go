@@ -1,59 +1,199 @@
 package log
 
 import (
+	"sync"
+
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
-var l *Logger
-
-func init() {
-	v := viper.New()
-	v.Set(ForceColorsKey, viper.GetBool(ForceColorsKey))
-	v.Set(DisableColorsKey, viper.GetBool(DisableColorsKey))
-	v.Set(DisableTimestampKey, viper.GetBool(DisableTimestampKey))
-	v.Set(ShortTimestampKey, viper.GetBool(ShortTimestampKey))
-	v.Set(TimestampFormatKey, viper.GetBool(TimestampFormatKey))
+var (
+	stdMu sync.Mutex
+	l     *Logger
+)
 
-	if viper.IsSet(OutputKey) {
-		v.Set(OutputKey, viper.Get(OutputKey))
-	}
-	if viper.IsSet(FilenameKey) {
-		v.Set(FilenameKey, viper.GetString(FilenameKey))
+// std returns the standard logger, creating it with the global viper values
+// the first time it's used
+func std() *Logger {
+	stdMu.Lock()
+	defer stdMu.Unlock()
+	if l == nil {
+		l = NewDefault()
 	}
-	if viper.IsSet(LevelKey) {
-		v.Set(LevelKey, viper.GetString(LevelKey))
-	}
-
-	l = New(v)
+	return l
 }
 
 // StdLogger return the standar logger
 func StdLogger() *Logger {
-	return l
+	return std()
+}
+
+// Configure replaces the standard logger with a new one configured from the
+// viper instance
+func Configure(v *viper.Viper) {
+	ReplaceStdLogger(New(v))
+}
+
+// ReplaceStdLogger replaces the standard logger
+func ReplaceStdLogger(logger *Logger) {
+	stdMu.Lock()
+	defer stdMu.Unlock()
+	l = logger
 }
 
 // NewEntryWithPrefix creates a new logrus.Entry with a prefix.
 func NewEntryWithPrefix(prefix string) *logrus.Entry {
-	return l.NewEntryWithPrefix(prefix)
+	return std().NewEntryWithPrefix(prefix)
 }
 
 // Prefix is an alias for NewEntryWithPrefix. It's used to print a message with
 // a different prefix
 func Prefix(prefix string) *logrus.Entry {
-	return l.Prefix(prefix)
+	return std().Prefix(prefix)
 }
 
 // GetPrefix return the prefix
 func GetPrefix() string {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	return l.prefix
+	return std().GetPrefix()
 }
 
 // SetPrefix sets the output prefix for the standard logger
 func SetPrefix(prefix string) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	l.prefix = prefix
+	std().SetPrefix(prefix)
+}
+
+// entry returns a new logrus.Entry of the standard logger with its prefix
+func entry() *logrus.Entry {
+	logger := std()
+	return logger.WithField(PrefixField, logger.GetPrefix())
+}
+
+// WithField creates an entry from the standard logger with its prefix and
+// adds a field to it
+func WithField(key string, value interface{}) *logrus.Entry {
+	return entry().WithField(key, value)
+}
+
+// WithFields creates an entry from the standard logger with its prefix and
+// adds multiple fields to it
+func WithFields(fields logrus.Fields) *logrus.Entry {
+	return entry().WithFields(fields)
+}
+
+// WithError creates an entry from the standard logger with its prefix and
+// adds an error to it
+func WithError(err error) *logrus.Entry {
+	return entry().WithError(err)
+}
+
+// Debug logs a message at level debug on the standard logger
+func Debug(args ...interface{}) {
+	entry().Debug(args...)
+}
+
+// Info logs a message at level info on the standard logger
+func Info(args ...interface{}) {
+	entry().Info(args...)
+}
+
+// Print logs a message at level info on the standard logger
+func Print(args ...interface{}) {
+	entry().Print(args...)
+}
+
+// Warn logs a message at level warning on the standard logger
+func Warn(args ...interface{}) {
+	entry().Warn(args...)
+}
+
+// Error logs a message at level error on the standard logger
+func Error(args ...interface{}) {
+	entry().Error(args...)
+}
+
+// Fatal logs a message at level fatal on the standard logger then the process exits
+func Fatal(args ...interface{}) {
+	entry().Fatal(args...)
+}
+
+// Panic logs a message at level panic on the standard logger then panics
+func Panic(args ...interface{}) {
+	entry().Panic(args...)
+}
+
+// Debugf logs a formatted message at level debug on the standard logger
+func Debugf(format string, args ...interface{}) {
+	entry().Debugf(format, args...)
+}
+
+// Infof logs a formatted message at level info on the standard logger
+func Infof(format string, args ...interface{}) {
+	entry().Infof(format, args...)
+}
+
+// Printf logs a formatted message at level info on the standard logger
+func Printf(format string, args ...interface{}) {
+	entry().Printf(format, args...)
+}
+
+// Warnf logs a formatted message at level warning on the standard logger
+func Warnf(format string, args ...interface{}) {
+	entry().Warnf(format, args...)
+}
+
+// Errorf logs a formatted message at level error on the standard logger
+func Errorf(format string, args ...interface{}) {
+	entry().Errorf(format, args...)
+}
+
+// Fatalf logs a formatted message at level fatal on the standard logger then the process exits
+func Fatalf(format string, args ...interface{}) {
+	entry().Fatalf(format, args...)
+}
+
+// Panicf logs a formatted message at level panic on the standard logger then panics
+func Panicf(format string, args ...interface{}) {
+	entry().Panicf(format, args...)
+}
+
+// Debugln logs a message at level debug on the standard logger. Spaces are
+// always added between operands
+func Debugln(args ...interface{}) {
+	entry().Debugln(args...)
+}
+
+// Infoln logs a message at level info on the standard logger. Spaces are
+// always added between operands
+func Infoln(args ...interface{}) {
+	entry().Infoln(args...)
+}
+
+// Println logs a message at level info on the standard logger. Spaces are
+// always added between operands
+func Println(args ...interface{}) {
+	entry().Println(args...)
+}
+
+// Warnln logs a message at level warning on the standard logger. Spaces are
+// always added between operands
+func Warnln(args ...interface{}) {
+	entry().Warnln(args...)
+}
+
+// Errorln logs a message at level error on the standard logger. Spaces are
+// always added between operands
+func Errorln(args ...interface{}) {
+	entry().Errorln(args...)
+}
+
+// Fatalln logs a message at level fatal on the standard logger then the process exits. Spaces are
+// always added between operands
+func Fatalln(args ...interface{}) {
+	entry().Fatalln(args...)
+}
+
+// Panicln logs a message at level panic on the standard logger then panics. Spaces are
+// always added between operands
+func Panicln(args ...interface{}) {
+	entry().Panicln(args...)
 }
@@ -0,0 +1,81 @@
+package log_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/spf13/viper"
+)
+
+func TestConfigure(t *testing.T) {
+	var expectedLogMessage string
+	var actualLogMessage string
+
+	defer log.ReplaceStdLogger(log.StdLogger())
+
+	var b bytes.Buffer
+	v := viper.New()
+	v.Set(log.OutputKey, &b)
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.LevelKey, "debug")
+	log.Configure(v)
+
+	log.Prefix("test").Debug("Debug")
+	expectedLogMessage = "DEBUG test: Debug\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	l := newLogger(&b, v)
+	log.ReplaceStdLogger(l)
+	if log.StdLogger() != l {
+		t.Errorf("Expected the standard logger to be replaced")
+	}
+	log.SetPrefix("std")
+	if l.GetPrefix() != "std" {
+		t.Errorf("Expected prefix 'std', but got '%s'", l.GetPrefix())
+	}
+}
+
+func TestExportedFunctions(t *testing.T) {
+	var expectedLogMessage string
+	var actualLogMessage string
+
+	defer log.ReplaceStdLogger(log.StdLogger())
+
+	var b bytes.Buffer
+	v := viper.New()
+	v.Set(log.OutputKey, &b)
+	v.Set(log.FormatKey, log.LogfmtFormat)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.LevelKey, "debug")
+	v.Set(log.PrefixField, "std")
+	log.Configure(v)
+
+	log.Debugf("Debug %d", 1)
+	log.Infoln("Info", 2)
+	log.Warn("Warn")
+	log.WithField("key", "value").Error("Error")
+	log.WithError(errors.New("failed")).Print("Print")
+	expectedLogMessage = "level=debug prefix=std msg=\"Debug 1\"\n" +
+		"level=info prefix=std msg=\"Info 2\"\n" +
+		"level=warning prefix=std msg=Warn\n" +
+		"level=error prefix=std msg=Error key=value\n" +
+		"level=info prefix=std msg=Print error=failed\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected Panicf to panic")
+		}
+	}()
+	log.Panicf("Panic %s", "now")
+}
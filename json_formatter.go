@@ -0,0 +1,76 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// JSONFormatter formats the logs in JSON, one object per line. The prefix is
+// printed as a top level key, like the caller if the logger reports it.
+type JSONFormatter struct {
+	// Disable timestamp logging. useful when output is redirected to logging
+	// system that already adds timestamps.
+	DisableTimestamp bool
+
+	// TimestampFormat to use for display when a full timestamp is printed
+	TimestampFormat string
+}
+
+// Format renders a single log entry
+func (f *JSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(logrus.Fields, len(entry.Data)+3)
+	for k, v := range entry.Data {
+		switch v := v.(type) {
+		case error:
+			// Otherwise errors are ignored by `encoding/json`
+			data[k] = v.Error()
+		default:
+			data[k] = v
+		}
+	}
+
+	prefixFieldClashes(data)
+	delete(data, "time")
+	delete(data, "msg")
+	delete(data, "level")
+
+	if prefix, ok := data[PrefixField]; ok && prefix == "" {
+		delete(data, PrefixField)
+	}
+
+	if entry.HasCaller() {
+		if c, ok := data[CallerField]; ok {
+			data["fields."+CallerField] = c
+		}
+		data[CallerField] = shortCaller(entry.Caller)
+	}
+
+	timestampFormat := f.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = time.RFC3339
+	}
+
+	if !f.DisableTimestamp {
+		data["time"] = entry.Time.Format(timestampFormat)
+	}
+	data["msg"] = entry.Message
+	data["level"] = entry.Level.String()
+
+	var b *bytes.Buffer
+	if entry.Buffer != nil {
+		b = entry.Buffer
+	} else {
+		b = &bytes.Buffer{}
+	}
+
+	encoder := json.NewEncoder(b)
+	if err := encoder.Encode(data); err != nil {
+		return nil, fmt.Errorf("failed to marshal fields to JSON, %v", err)
+	}
+
+	return b.Bytes(), nil
+}
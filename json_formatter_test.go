@@ -0,0 +1,76 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func TestJSONFormatter(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.FormatKey, log.JSONFormat)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.LevelKey, "debug")
+	l := newLogger(&b, v)
+
+	l.Prefix("test").WithFields(logrus.Fields{"key": "value", "msg": "clash", "err": errors.New("failed")}).Warn("Warning")
+
+	var actual map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &actual); err != nil {
+		t.Fatalf("Cannot unmarshal JSON log entry '%s'. %s", b.String(), err)
+	}
+
+	expected := map[string]interface{}{
+		"prefix":     "test",
+		"level":      "warning",
+		"msg":        "Warning",
+		"key":        "value",
+		"fields.msg": "clash",
+		"err":        "failed",
+	}
+	if len(actual) != len(expected) {
+		t.Errorf("Expected %d keys, but got %d in '%s'", len(expected), len(actual), b.String())
+	}
+	for k, e := range expected {
+		if actual[k] != e {
+			t.Errorf("Expected '%s' to be '%v', but got '%v'", k, e, actual[k])
+		}
+	}
+}
+
+func TestJSONFormatterTimestamp(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.FormatKey, log.JSONFormat)
+	v.Set(log.TimestampFormatKey, "2006")
+	v.Set(log.LevelKey, "debug")
+	l := newLogger(&b, v)
+
+	l.Info("Information")
+
+	var actual map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &actual); err != nil {
+		t.Fatalf("Cannot unmarshal JSON log entry '%s'. %s", b.String(), err)
+	}
+	if ts, ok := actual["time"].(string); !ok || len(ts) != 4 {
+		t.Errorf("Expected a timestamp with format '2006', but got '%v'", actual["time"])
+	}
+	if _, ok := actual["prefix"]; ok {
+		t.Errorf("Expected no prefix for an empty prefix, but got '%v'", actual["prefix"])
+	}
+
+	b.Reset()
+	c := l.Copy()
+	c.Info("Information")
+	if err := json.Unmarshal(b.Bytes(), &actual); err != nil {
+		t.Errorf("Expected the copy to keep the JSON format, but got '%s'. %s", b.String(), err)
+	}
+}
@@ -0,0 +1,261 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LevelState is the logger state returned by the LevelHandler
+type LevelState struct {
+	Level     string            `json:"level"`
+	Prefix    string            `json:"prefix"`
+	Levels    map[string]string `json:"levels"`
+	Format    string            `json:"format"`
+	Formatter *FormatterState   `json:"formatter,omitempty"`
+	RevertAt  *time.Time        `json:"revert_at,omitempty"`
+}
+
+// FormatterState is the configuration of the formatters of this package
+// returned by the LevelHandler, with the values of the viper variables. It's
+// nil for any other formatter.
+type FormatterState struct {
+	DisableTimestamp bool   `json:"notimestamp"`
+	TimestampFormat  string `json:"formattimestamp,omitempty"`
+
+	// Text formatter options
+	ForceColors      bool   `json:"color,omitempty"`
+	DisableColors    bool   `json:"nocolor,omitempty"`
+	TimestampMode    string `json:"timestamp_mode,omitempty"`
+	ColorLevel       string `json:"color_level,omitempty"`
+	Multiline        string `json:"multiline,omitempty"`
+	AbbreviatePrefix bool   `json:"abbreviate_prefix,omitempty"`
+	ValueFormat      string `json:"value_format,omitempty"`
+}
+
+// LevelChange is the request accepted by the LevelHandler to change the logger
+// level or the level of some prefixes. An empty level for a prefix removes its
+// level. If TTL is set, such as "10m", the previous levels are restored after
+// that time.
+type LevelChange struct {
+	Level  string            `json:"level,omitempty"`
+	Levels map[string]string `json:"levels,omitempty"`
+	TTL    string            `json:"ttl,omitempty"`
+}
+
+// levelsRevert has the levels to restore when the timer expires
+type levelsRevert struct {
+	timer  *time.Timer
+	at     time.Time
+	base   logrus.Level
+	levels map[string]logrus.Level
+}
+
+// LevelHandler returns an http.Handler to inspect and change the logger levels
+// at runtime. A GET request returns the LevelState in JSON, a PUT or POST
+// request with a LevelChange in JSON changes the levels and returns the new
+// LevelState.
+func (logger *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var change LevelChange
+			if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+				writeLevelError(w, http.StatusBadRequest, fmt.Errorf("invalid request. %s", err))
+				return
+			}
+			if err := logger.changeLevels(change); err != nil {
+				writeLevelError(w, http.StatusBadRequest, err)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			writeLevelError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+
+		state, err := json.Marshal(logger.levelState())
+		if err != nil {
+			writeLevelError(w, http.StatusInternalServerError, fmt.Errorf("cannot encode the logger state. %s", err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(append(state, '\n'))
+	})
+}
+
+// formatterState returns the configuration of the known formatters
+func formatterState(formatter logrus.Formatter) *FormatterState {
+	switch formatter := formatter.(type) {
+	case *TextFormatter:
+		mode := formatter.TimestampMode
+		if mode == "" && formatter.ShortTimestamp {
+			mode = TimestampShort
+		}
+		return &FormatterState{
+			DisableTimestamp: formatter.DisableTimestamp,
+			TimestampFormat:  formatter.TimestampFormat,
+			ForceColors:      formatter.ForceColors,
+			DisableColors:    formatter.DisableColors,
+			TimestampMode:    mode,
+			ColorLevel:       formatter.ColorLevel.String(),
+			Multiline:        formatter.Multiline,
+			AbbreviatePrefix: formatter.AbbreviatePrefix,
+			ValueFormat:      formatter.ValueFormat,
+		}
+	case *JSONFormatter:
+		return &FormatterState{
+			DisableTimestamp: formatter.DisableTimestamp,
+			TimestampFormat:  formatter.TimestampFormat,
+		}
+	case *LogfmtFormatter:
+		return &FormatterState{
+			DisableTimestamp: formatter.DisableTimestamp,
+			TimestampFormat:  formatter.TimestampFormat,
+		}
+	default:
+		return nil
+	}
+}
+
+// formatName returns the FormatKey value of the formatter
+func formatName(formatter logrus.Formatter) string {
+	switch formatter.(type) {
+	case *TextFormatter:
+		return TextFormat
+	case *JSONFormatter:
+		return JSONFormat
+	case *LogfmtFormatter:
+		return LogfmtFormat
+	default:
+		return fmt.Sprintf("%T", formatter)
+	}
+}
+
+func writeLevelError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// levelState returns the current logger state
+func (logger *Logger) levelState() LevelState {
+	formatter := logger.formatter()
+	state := LevelState{
+		Level:     prefixLevelName(logger.GetLevel()),
+		Prefix:    logger.GetPrefix(),
+		Levels:    map[string]string{},
+		Format:    formatName(formatter),
+		Formatter: formatterState(formatter),
+	}
+	for prefix, level := range logger.PrefixLevels() {
+		state.Levels[prefix] = prefixLevelName(level)
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if logger.revert != nil {
+		at := logger.revert.at
+		state.RevertAt = &at
+	}
+
+	return state
+}
+
+// changeLevels validates and applies the change, scheduling the revert if it
+// has a TTL
+func (logger *Logger) changeLevels(change LevelChange) error {
+	var base *logrus.Level
+	if change.Level != "" {
+		level, err := ParsePrefixLevel(change.Level)
+		if err != nil {
+			return err
+		}
+		base = &level
+	}
+	levels := map[string]*logrus.Level{}
+	for prefix, name := range change.Levels {
+		if name == "" {
+			levels[prefix] = nil
+			continue
+		}
+		level, err := ParsePrefixLevel(name)
+		if err != nil {
+			return fmt.Errorf("invalid level for prefix %q. %s", prefix, err)
+		}
+		levels[prefix] = &level
+	}
+	var ttl time.Duration
+	if change.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(change.TTL); err != nil || ttl <= 0 {
+			return fmt.Errorf("invalid ttl %q", change.TTL)
+		}
+	}
+
+	logger.scheduleRevert(ttl)
+
+	rules := logger.levelRules()
+	if base != nil {
+		rules.setBase(*base)
+	}
+	for prefix, level := range levels {
+		if level == nil {
+			rules.clear(prefix)
+		} else {
+			rules.set(prefix, *level)
+		}
+	}
+	logger.applyLevels()
+
+	return nil
+}
+
+// scheduleRevert restores the current levels after the ttl. If there is a
+// revert pending, it keeps the levels to restore but moves the time. If the
+// ttl is zero, the pending revert is cancelled so the change is permanent.
+func (logger *Logger) scheduleRevert(ttl time.Duration) {
+	base, levels := logger.GetLevel(), logger.PrefixLevels()
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	revert := logger.revert
+	if revert != nil {
+		revert.timer.Stop()
+	}
+	if ttl == 0 {
+		logger.revert = nil
+		return
+	}
+	if revert == nil {
+		revert = &levelsRevert{
+			base:   base,
+			levels: levels,
+		}
+	}
+
+	revert.at = time.Now().Add(ttl)
+	revert.timer = time.AfterFunc(ttl, func() {
+		logger.mu.Lock()
+		if logger.revert != revert {
+			logger.mu.Unlock()
+			return
+		}
+		logger.revert = nil
+		logger.mu.Unlock()
+
+		rules := logger.levelRules()
+		rules.clearAll()
+		for prefix, level := range revert.levels {
+			rules.set(prefix, level)
+		}
+		rules.setBase(revert.base)
+		logger.applyLevels()
+	})
+	logger.revert = revert
+}
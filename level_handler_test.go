@@ -0,0 +1,137 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johandry/log"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func levelRequest(t *testing.T, h http.Handler, method, body string) (int, log.LevelState) {
+	req := httptest.NewRequest(method, "/log/level", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var state log.LevelState
+	if rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(&state); err != nil {
+			t.Fatalf("Cannot decode the response '%s'. %s", rec.Body.String(), err)
+		}
+	}
+	return rec.Code, state
+}
+
+func TestLevelHandler(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+	v.Set(log.LevelKey, "info")
+	l := newLogger(&b, v)
+	l.SetPrefix("main")
+	h := l.LevelHandler()
+
+	code, state := levelRequest(t, h, http.MethodGet, "")
+	if code != http.StatusOK || state.Level != "info" || state.Prefix != "main" || len(state.Levels) != 0 || state.Format != log.TextFormat {
+		t.Errorf("Expected level info and prefix main, but got %d %+v", code, state)
+	}
+
+	code, state = levelRequest(t, h, http.MethodPut, `{"level": "warn", "levels": {"db": "debug", "http/**": "off"}}`)
+	if code != http.StatusOK || state.Level != "warning" || state.Levels["db"] != "debug" || state.Levels["http/**"] != "off" {
+		t.Errorf("Expected the new levels, but got %d %+v", code, state)
+	}
+	if l.GetLevel() != logrus.WarnLevel || l.PrefixLevel("db") != logrus.DebugLevel {
+		t.Errorf("Expected the logger levels to change, but got %s and %v", l.GetLevel(), l.PrefixLevels())
+	}
+
+	code, state = levelRequest(t, h, http.MethodPost, `{"levels": {"db": ""}}`)
+	if _, ok := state.Levels["db"]; code != http.StatusOK || ok {
+		t.Errorf("Expected the db level to be removed, but got %d %+v", code, state)
+	}
+
+	if code, _ = levelRequest(t, h, http.MethodPut, `{"level": "verbose"}`); code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an invalid level, but got %d", http.StatusBadRequest, code)
+	}
+	if code, _ = levelRequest(t, h, http.MethodDelete, ""); code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d for DELETE, but got %d", http.StatusMethodNotAllowed, code)
+	}
+}
+
+func TestLevelHandlerTTL(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+	v.Set(log.LevelKey, "info")
+	l := newLogger(&b, v)
+	h := l.LevelHandler()
+
+	code, state := levelRequest(t, h, http.MethodPut, `{"level": "debug", "levels": {"db": "trace"}, "ttl": "50ms"}`)
+	if code != http.StatusOK || state.Level != "debug" || state.RevertAt == nil {
+		t.Errorf("Expected a temporary debug level, but got %d %+v", code, state)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for l.GetLevel() != logrus.InfoLevel && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if l.GetLevel() != logrus.InfoLevel || len(l.PrefixLevels()) != 0 {
+		t.Errorf("Expected the levels to revert, but got %s and %v", l.GetLevel(), l.PrefixLevels())
+	}
+	if _, state = levelRequest(t, h, http.MethodGet, ""); state.RevertAt != nil {
+		t.Errorf("Expected no pending revert, but got %v", state.RevertAt)
+	}
+}
+
+func TestLevelHandlerFormatter(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.TimestampModeKey, log.TimestampDelta)
+	l := newLogger(&b, v)
+	h := l.LevelHandler()
+
+	code, state := levelRequest(t, h, http.MethodGet, "")
+	expected := log.FormatterState{DisableColors: true, TimestampMode: log.TimestampDelta, ColorLevel: "auto"}
+	if code != http.StatusOK || state.Formatter == nil || *state.Formatter != expected {
+		t.Errorf("Expected the formatter state %+v, but got %d %+v", expected, code, state.Formatter)
+	}
+
+	// a formatter with functions cannot be encoded, it's not returned
+	l.SetFormatter(&logrus.TextFormatter{CallerPrettyfier: func(*runtime.Frame) (string, string) { return "", "" }})
+	code, state = levelRequest(t, h, http.MethodGet, "")
+	if code != http.StatusOK || state.Formatter != nil || state.Format != "*logrus.TextFormatter" {
+		t.Errorf("Expected no formatter state, but got %d %+v", code, state)
+	}
+}
+
+func TestLevelHandlerRoundTrip(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+	l := newLogger(&b, v)
+	l.SetLevel(log.OffLevel)
+	l.SetPrefixLevel("db", log.OffLevel)
+	l.SetPrefixLevel("http/**", logrus.DebugLevel)
+	h := l.LevelHandler()
+
+	// the state returned by GET is accepted back unchanged
+	req := httptest.NewRequest(http.MethodGet, "/log/level", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, `"level":"off"`) {
+		t.Fatalf("Expected the off level, but got %d '%s'", rec.Code, body)
+	}
+
+	code, state := levelRequest(t, h, http.MethodPut, body)
+	if code != http.StatusOK || state.Level != "off" || state.Levels["db"] != "off" || state.Levels["http/**"] != "debug" {
+		t.Errorf("Expected the same levels, but got %d %+v", code, state)
+	}
+	if level := l.GetLevel(); level != log.OffLevel {
+		t.Errorf("Expected the off level, but got %v", level)
+	}
+}
@@ -0,0 +1,354 @@
+package log
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OffLevel used as the level of a prefix silences all its entries
+const OffLevel = logrus.Level(math.MaxUint32)
+
+// ParsePrefixLevel takes a level name and returns the logrus level, or
+// OffLevel for "off"
+func ParsePrefixLevel(name string) (logrus.Level, error) {
+	switch strings.ToLower(name) {
+	case "off", "none":
+		return OffLevel, nil
+	}
+	level, err := logrus.ParseLevel(name)
+	if err != nil {
+		return level, fmt.Errorf("not a valid level or %q: %q", "off", name)
+	}
+	return level, nil
+}
+
+// prefixLevelName returns the name of a prefix level
+func prefixLevelName(level logrus.Level) string {
+	if level == OffLevel {
+		return "off"
+	}
+	return level.String()
+}
+
+// prefixLevels has the log level of the logger and the level overrides for
+// some prefixes. The prefixes could be exact names, glob patterns such as
+// "db*" or trees such as "server/**" to match a prefix and its descendants.
+type prefixLevels struct {
+	mu       sync.RWMutex
+	base     logrus.Level
+	exact    map[string]logrus.Level
+	patterns []prefixPattern
+
+	// cache has the level of the prefixes already looked up, it's cleared
+	// when it has maxCachedPrefixes so dynamic prefixes don't grow it forever
+	cache map[string]logrus.Level
+}
+
+// maxCachedPrefixes is the maximum number of prefix levels in the cache
+const maxCachedPrefixes = 1024
+
+type prefixPattern struct {
+	pattern string
+	level   logrus.Level
+}
+
+func newPrefixLevels(base logrus.Level) *prefixLevels {
+	return &prefixLevels{
+		base:  base,
+		exact: map[string]logrus.Level{},
+		cache: map[string]logrus.Level{},
+	}
+}
+
+// isGlob returns true if the prefix is a glob pattern
+func isGlob(prefix string) bool {
+	return strings.ContainsAny(prefix, "*?[")
+}
+
+// level returns the level for the prefix. Without overrides it's the logger
+// level, and the prefix is not cached.
+func (p *prefixLevels) level(prefix string) logrus.Level {
+	p.mu.RLock()
+	if len(p.exact) == 0 && len(p.patterns) == 0 {
+		defer p.mu.RUnlock()
+		return p.base
+	}
+	level, ok := p.cache[prefix]
+	p.mu.RUnlock()
+	if ok {
+		return level
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	level = p.lookup(prefix)
+	if len(p.cache) >= maxCachedPrefixes {
+		p.cache = map[string]logrus.Level{}
+	}
+	p.cache[prefix] = level
+	return level
+}
+
+// lookup returns the level of the exact prefix, or of the longest pattern
+// matching the prefix or the logger level
+func (p *prefixLevels) lookup(prefix string) logrus.Level {
+	if level, ok := p.exact[prefix]; ok {
+		return level
+	}
+	for _, pp := range p.patterns {
+		if matchPrefix(pp.pattern, prefix) {
+			return pp.level
+		}
+	}
+	return p.base
+}
+
+// matchPrefix returns true if the prefix matches the glob or tree pattern
+func matchPrefix(pattern, prefix string) bool {
+	if tree := strings.TrimSuffix(pattern, PrefixSeparator+treeSuffix); tree != pattern {
+		if prefix == tree || strings.HasPrefix(prefix, tree+PrefixSeparator) {
+			return true
+		}
+	}
+	ok, _ := path.Match(pattern, prefix)
+	return ok
+}
+
+// enabled returns true if an entry with the prefix and level should be printed
+func (p *prefixLevels) enabled(prefix string, level logrus.Level) bool {
+	max := p.level(prefix)
+	return max != OffLevel && level <= max
+}
+
+// set sets the level for a prefix or pattern
+func (p *prefixLevels) set(prefix string, level logrus.Level) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !isGlob(prefix) {
+		p.exact[prefix] = level
+	} else {
+		p.removePattern(prefix)
+		p.patterns = append(p.patterns, prefixPattern{pattern: prefix, level: level})
+		sort.SliceStable(p.patterns, func(i, j int) bool {
+			return len(p.patterns[i].pattern) > len(p.patterns[j].pattern)
+		})
+	}
+	p.cache = map[string]logrus.Level{}
+}
+
+// clear removes the level for a prefix or pattern
+func (p *prefixLevels) clear(prefix string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.exact, prefix)
+	p.removePattern(prefix)
+	p.cache = map[string]logrus.Level{}
+}
+
+// clearAll removes the levels for all the prefixes
+func (p *prefixLevels) clearAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.exact = map[string]logrus.Level{}
+	p.patterns = nil
+	p.cache = map[string]logrus.Level{}
+}
+
+// setBase sets the level of the prefixes without an override
+func (p *prefixLevels) setBase(level logrus.Level) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.base = level
+	p.cache = map[string]logrus.Level{}
+}
+
+func (p *prefixLevels) removePattern(pattern string) {
+	for i, pp := range p.patterns {
+		if pp.pattern == pattern {
+			p.patterns = append(p.patterns[:i], p.patterns[i+1:]...)
+			return
+		}
+	}
+}
+
+// filtered returns true if the logrus level is not enough to drop the
+// disabled entries: there are overrides or the logger level is OffLevel
+func (p *prefixLevels) filtered() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.exact) != 0 || len(p.patterns) != 0 || p.base == OffLevel
+}
+
+// max returns the most verbose level of the logger and the overrides, it's
+// the level required by logrus to not discard the entries of any prefix
+func (p *prefixLevels) max() logrus.Level {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	max := p.base
+	if max == OffLevel {
+		max = logrus.PanicLevel
+	}
+	for _, level := range p.exact {
+		if level > max && level != OffLevel {
+			max = level
+		}
+	}
+	for _, pp := range p.patterns {
+		if pp.level > max && pp.level != OffLevel {
+			max = pp.level
+		}
+	}
+	return max
+}
+
+// all returns a copy of the overrides
+func (p *prefixLevels) all() map[string]logrus.Level {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	levels := make(map[string]logrus.Level, len(p.exact)+len(p.patterns))
+	for prefix, level := range p.exact {
+		levels[prefix] = level
+	}
+	for _, pp := range p.patterns {
+		levels[pp.pattern] = pp.level
+	}
+	return levels
+}
+
+// levelFormatter drops the entries disabled by the prefix levels before they
+// get formatted. The hooks of this package skip the disabled entries, but the
+// hooks added by the user are called before the formatter, so they still get
+// the entries of the prefixes with a less verbose level than the logger.
+type levelFormatter struct {
+	logrus.Formatter
+	levels *prefixLevels
+}
+
+// Format returns nothing for the disabled entries, otherwise it formats the
+// entry with the logger formatter
+func (f *levelFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	prefix, _ := entry.Data[PrefixField].(string)
+	if !f.levels.enabled(prefix, entry.Level) {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
+
+// entryEnabled returns true if the entry is printed with the prefix levels
+func (logger *Logger) entryEnabled(entry *logrus.Entry) bool {
+	prefix, _ := entry.Data[PrefixField].(string)
+	return logger.levelRules().enabled(prefix, entry.Level)
+}
+
+// levelRules returns the level and prefix levels of the logger
+func (logger *Logger) levelRules() *prefixLevels {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if logger.levels == nil {
+		logger.levels = newPrefixLevels(logger.Logger.GetLevel())
+	}
+	return logger.levels
+}
+
+// formatter returns the formatter of the logger without the prefix levels
+// filter
+func (logger *Logger) formatter() logrus.Formatter {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	return logger.unwrapFormatter()
+}
+
+func (logger *Logger) unwrapFormatter() logrus.Formatter {
+	if f, ok := logger.Formatter.(*levelFormatter); ok {
+		return f.Formatter
+	}
+	return logger.Formatter
+}
+
+// applyLevels sets the logrus level and the formatter filter required by the
+// prefix levels
+func (logger *Logger) applyLevels() {
+	levels := logger.levelRules()
+
+	logger.mu.Lock()
+	formatter := logger.unwrapFormatter()
+	if !levels.filtered() {
+		logger.Logger.SetFormatter(formatter)
+	} else {
+		logger.Logger.SetFormatter(&levelFormatter{Formatter: formatter, levels: levels})
+	}
+	logger.mu.Unlock()
+
+	logger.Logger.SetLevel(levels.max())
+}
+
+// SetLevel sets the logger level, used by the prefixes without a level
+func (logger *Logger) SetLevel(level logrus.Level) {
+	logger.levelRules().setBase(level)
+	logger.applyLevels()
+}
+
+// GetLevel returns the logger level, used by the prefixes without a level
+func (logger *Logger) GetLevel() logrus.Level {
+	levels := logger.levelRules()
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+	return levels.base
+}
+
+// SetFormatter sets the logger formatter
+func (logger *Logger) SetFormatter(formatter logrus.Formatter) {
+	logger.mu.Lock()
+	withClock(formatter, logger.unwrapFormatter())
+	logger.Logger.SetFormatter(formatter)
+	logger.mu.Unlock()
+	logger.applyLevels()
+}
+
+// SetPrefixLevel sets the level for the entries with the given prefix. The
+// prefix could be a glob pattern, like "db*", to set the level of all the
+// matching prefixes, or a tree like "server/**" to set the level of a prefix
+// and all its descendants. An exact prefix has precedence over the patterns
+// and the longest pattern over the shorter ones. Use OffLevel to silence the
+// prefix.
+//
+// The entries are filtered after the hooks are called, so the hooks added
+// with AddHook still get the entries disabled by a prefix level.
+func (logger *Logger) SetPrefixLevel(prefix string, level logrus.Level) {
+	logger.levelRules().set(prefix, level)
+	logger.applyLevels()
+}
+
+// ClearPrefixLevel removes the level for the given prefix or pattern
+func (logger *Logger) ClearPrefixLevel(prefix string) {
+	logger.levelRules().clear(prefix)
+	logger.applyLevels()
+}
+
+// ClearPrefixLevels removes the level for all the prefixes
+func (logger *Logger) ClearPrefixLevels() {
+	logger.levelRules().clearAll()
+	logger.applyLevels()
+}
+
+// PrefixLevels returns the levels set for the prefixes and patterns
+func (logger *Logger) PrefixLevels() map[string]logrus.Level {
+	return logger.levelRules().all()
+}
+
+// PrefixLevel returns the level used for the entries with the given prefix
+func (logger *Logger) PrefixLevel(prefix string) logrus.Level {
+	return logger.levelRules().level(prefix)
+}
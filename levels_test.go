@@ -0,0 +1,189 @@
+package log_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func TestPrefixLevels(t *testing.T) {
+	var expectedLogMessage string
+	var actualLogMessage string
+
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.FormatKey, log.LogfmtFormat)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.LevelKey, "info")
+	v.Set(log.LevelsKey, map[string]interface{}{"db": "debug", "http*": "warn"})
+	l := newLogger(&b, v)
+
+	l.Prefix("db").Debug("Query")
+	l.Prefix("http").Info("Request")
+	l.Prefix("https").Warn("Insecure")
+	l.Prefix("main").Debug("Hidden")
+	l.Prefix("main").Info("Started")
+	expectedLogMessage = "level=debug prefix=db msg=Query\n" +
+		"level=warning prefix=https msg=Insecure\n" +
+		"level=info prefix=main msg=Started\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	if l.GetLevel() != logrus.InfoLevel {
+		t.Errorf("Expected the logger level to be info, but got %s", l.GetLevel())
+	}
+
+	l.SetPrefixLevel("https", logrus.DebugLevel)
+	l.ClearPrefixLevel("db")
+	l.Prefix("db").Debug("Query")
+	l.Prefix("https").Debug("Handshake")
+	l.Prefix("http").Info("Request")
+	expectedLogMessage = "level=debug prefix=https msg=Handshake\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	c := l.Copy()
+	if c.PrefixLevel("https") != logrus.DebugLevel || c.PrefixLevel("http2") != logrus.WarnLevel {
+		t.Errorf("Expected the copy to have the same prefix levels, but got %v", c.PrefixLevels())
+	}
+
+	l.ClearPrefixLevels()
+	l.Prefix("https").Debug("Handshake")
+	l.Prefix("http").Info("Request")
+	expectedLogMessage = "level=info prefix=http msg=Request\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	if _, ok := l.Formatter.(*log.LogfmtFormatter); !ok {
+		t.Errorf("Expected the formatter to be restored without prefix levels, but got %T", l.Formatter)
+	}
+}
+
+type stackRecorder struct {
+	stacks int
+}
+
+func (h *stackRecorder) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *stackRecorder) Fire(entry *logrus.Entry) error {
+	if _, ok := entry.Data[log.StackField]; ok {
+		h.stacks++
+	}
+	return nil
+}
+
+func TestPrefixLevelsHooks(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.FormatKey, log.LogfmtFormat)
+	v.Set(log.LevelsKey, map[string]interface{}{"db": "off"})
+	v.Set(log.StackTraceLevelKey, "error")
+	v.Set(log.CallerKey, true)
+	l := newLogger(&b, v)
+	recorder := &stackRecorder{}
+	l.AddHook(recorder)
+
+	l.Prefix("db").Error("Hidden")
+	if b.Len() != 0 {
+		t.Errorf("Expected no entries, but got '%s'", b.String())
+	}
+	if recorder.stacks != 0 {
+		t.Errorf("Expected no stack trace for the disabled entries, but got %d", recorder.stacks)
+	}
+
+	l.Prefix("main").Error("Printed")
+	if recorder.stacks != 1 {
+		t.Errorf("Expected a stack trace for the enabled entries, but got %d", recorder.stacks)
+	}
+}
+
+func TestOffLevel(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.FormatKey, log.LogfmtFormat)
+	v.Set(log.DisableTimestampKey, true)
+	l := newLogger(&b, v)
+	l.SetLevel(log.OffLevel)
+
+	l.Trace("Hidden")
+	l.Error("Hidden")
+	if b.Len() != 0 {
+		t.Errorf("Expected no entries with the off level, but got '%s'", b.String())
+	}
+
+	l.SetPrefixLevel("db", logrus.DebugLevel)
+	l.Prefix("db").Debug("Query")
+	l.Prefix("main").Error("Hidden")
+	expectedLogMessage := "level=debug prefix=db msg=Query\n"
+	if actualLogMessage := b.String(); actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+}
+
+func TestOffLevelConfig(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.FormatKey, log.LogfmtFormat)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.LevelKey, "off")
+	if err := log.Validate(v); err != nil {
+		t.Errorf("Expected the off level to be valid, but got %s", err)
+	}
+	l := newLogger(&b, v)
+	l.Error("Hidden")
+	if b.Len() != 0 {
+		t.Errorf("Expected no entries with the off level, but got '%s'", b.String())
+	}
+
+	v.Set(log.LevelKey, "info")
+	l.Reload(v)
+	v.Set(log.LevelKey, "off")
+	changed, failed := l.Reload(v)
+	if len(changed) != 1 || changed[0] != log.LevelKey || len(failed) != 0 {
+		t.Errorf("Expected %s to change, but got %v and failures %v", log.LevelKey, changed, failed)
+	}
+	l.Error("Hidden")
+	if b.Len() != 0 {
+		t.Errorf("Expected no entries with the reloaded off level, but got '%s'", b.String())
+	}
+}
+
+func TestPrefixLevelsManyPrefixes(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.FormatKey, log.LogfmtFormat)
+	v.Set(log.DisableTimestampKey, true)
+	l := newLogger(&b, v)
+	l.SetPrefixLevel("db/**", logrus.DebugLevel)
+
+	// the levels of the dynamic prefixes are still right after the cache is full
+	for i := 0; i < 3000; i++ {
+		l.Prefix(fmt.Sprintf("request-%d", i)).Debug("Hidden")
+		log.SubPrefix(l.Prefix("db"), fmt.Sprintf("query-%d", i)).Trace("Hidden")
+	}
+	l.Prefix("request-1").Info("Request")
+	log.SubPrefix(l.Prefix("db"), "query-1").Debug("Query")
+	expectedLogMessage := "level=info prefix=request-1 msg=Request\n" +
+		"level=debug prefix=db/query-1 msg=Query\n"
+	if actualLogMessage := b.String(); actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+}
@@ -1,8 +1,8 @@
 package log
 
 import (
+	"bufio"
 	"io"
-	"os"
 	"sync"
 
 	"github.com/sirupsen/logrus"
@@ -21,6 +21,70 @@ import (
 // short format
 // TimestampFormatKey is the viper variable used to define the log timestamp
 // format
+// FormatKey is the viper variable used to define the log format. It could be
+// "text" (default), "json" or "logfmt"
+// MaxSizeKey is the viper variable used to define the maximum size in megabytes
+// of the log file before it gets rotated
+// MaxAgeKey is the viper variable used to define the maximum number of days to
+// retain the rotated log files
+// MaxBackupsKey is the viper variable used to define the maximum number of
+// rotated log files to retain
+// DailyKey is the viper variable used to define if the log file should be
+// rotated every day
+// CompressKey is the viper variable used to define if the rotated log files
+// should be compressed with gzip
+// ReopenKey is the viper variable used to define if the log file should be
+// reopened when the process receives SIGHUP
+// SniffLevelsKey is the viper variable used to define if the level of the
+// messages from a standard library logger is taken from words such as [ERROR]
+// InputKey is the viper variable used to define the input to read the answers
+// of Ask and AskSecret
+// RawOutputKey is the viper variable used to define if the cli.Ui Output method
+// prints the raw messages to the standard output instead of log entries
+// UIOutputKey is the viper variable used to define the writer where the cli.Ui
+// Output method prints the raw messages
+// LevelsKey is the viper variable used to define the log level of some
+// prefixes, it's a map of prefixes or glob patterns to level names
+// AbbreviatePrefixKey is the viper variable used to define if the hierarchical
+// prefixes are abbreviated by the text formatter
+// CallerKey is the viper variable used to define if the file and line of the
+// code printing the entry is reported
+// StackTraceLevelKey is the viper variable used to define the level of the
+// least severe entries printed with a stack trace, such as "error"
+// MultilineKey is the viper variable used to define how the text formatter
+// prints the messages and values with newlines. It could be "raw" (default),
+// "indent", "header" or "escape"
+// ThemeKey is the viper variable used to define the color theme of the text
+// formatter. It could be "default", "light", "dark" or "solarized"
+// ColorsKey is the viper variable used to define the colors of the theme, it's
+// a map of theme colors such as "info" or "prefix" to mgutz/ansi styles
+// ColorLevelKey is the viper variable used to define the colors supported by
+// the terminal instead of detecting them. It could be "auto" (default),
+// "none", "16", "256" or "truecolor"
+// HashPrefixColorsKey is the viper variable used to define if the text
+// formatter prints every prefix with a color taken from a hash of the prefix
+// PrefixPaletteKey is the viper variable used to define the list of colors
+// given to the prefixes when they are hashed
+// PrefixColorsKey is the viper variable used to define the color of some
+// prefixes, it's a map of prefixes to mgutz/ansi styles
+// PrefixWidthKey is the viper variable used to define the width of the prefix
+// column of the text formatter
+// AutoPrefixWidthKey is the viper variable used to define if the prefix column
+// grows to the longest prefix, up to the prefix width if it's set
+// RightAlignLevelKey is the viper variable used to define if the level names
+// are aligned to the right
+// FieldsColumnKey is the viper variable used to define the column where the
+// text formatter prints the fields
+// TimestampModeKey is the viper variable used to define how the text formatter
+// prints the timestamp. It could be "full" (default), "short", "elapsed",
+// "delta", "utc", "local" or "rfc3339nano"
+// ValueFormatKey is the viper variable used to define how the text formatter
+// prints the structs, maps and slices in the fields. It could be "default",
+// "json", "verbose" or "expand"
+// MaxValueDepthKey is the viper variable used to define the nesting level of
+// the values printed in JSON or expanded
+// MaxValueLengthKey is the viper variable used to define the maximum number of
+// characters of the values printed by the text formatter
 const (
 	OutputKey           = "log_output"
 	FilenameKey         = "log_filename"
@@ -30,6 +94,75 @@ const (
 	DisableTimestampKey = "log_notimestamp"
 	ShortTimestampKey   = "log_shorttimestamp"
 	TimestampFormatKey  = "log_formattimestamp"
+	FormatKey           = "log_format"
+	MaxSizeKey          = "log_maxsize"
+	MaxAgeKey           = "log_maxage"
+	MaxBackupsKey       = "log_maxbackups"
+	DailyKey            = "log_daily"
+	CompressKey         = "log_compress"
+	ReopenKey           = "log_reopen"
+	SniffLevelsKey      = "log_sniff_levels"
+	InputKey            = "log_input"
+	RawOutputKey        = "log_ui_raw"
+	UIOutputKey         = "log_ui_output"
+	LevelsKey           = "log_levels"
+	AbbreviatePrefixKey = "log_abbreviate_prefix"
+	CallerKey           = "log_caller"
+	StackTraceLevelKey  = "log_stacktrace_level"
+	MultilineKey        = "log_multiline"
+	ThemeKey            = "log_theme"
+	ColorsKey           = "log_colors"
+	ColorLevelKey       = "log_color_level"
+	HashPrefixColorsKey = "log_prefix_color_hash"
+	PrefixPaletteKey    = "log_prefix_palette"
+	PrefixColorsKey     = "log_prefix_colors"
+	PrefixWidthKey      = "log_prefix_width"
+	AutoPrefixWidthKey  = "log_prefix_auto_width"
+	RightAlignLevelKey  = "log_level_right_align"
+	FieldsColumnKey     = "log_fields_column"
+	TimestampModeKey    = "log_timestamp_mode"
+	ValueFormatKey      = "log_value_format"
+	MaxValueDepthKey    = "log_value_depth"
+	MaxValueLengthKey   = "log_value_length"
+)
+
+// TextFormat, JSONFormat and LogfmtFormat are the values accepted by the
+// FormatKey viper variable
+const (
+	TextFormat   = "text"
+	JSONFormat   = "json"
+	LogfmtFormat = "logfmt"
+)
+
+// MultilineRaw, MultilineIndent, MultilineHeader and MultilineEscape are the
+// values accepted by the MultilineKey viper variable
+const (
+	MultilineRaw    = "raw"
+	MultilineIndent = "indent"
+	MultilineHeader = "header"
+	MultilineEscape = "escape"
+)
+
+// TimestampFull, TimestampShort, TimestampElapsed, TimestampDelta,
+// TimestampUTC, TimestampLocal and TimestampRFC3339Nano are the values accepted
+// by the TimestampModeKey viper variable
+const (
+	TimestampFull        = "full"
+	TimestampShort       = "short"
+	TimestampElapsed     = "elapsed"
+	TimestampDelta       = "delta"
+	TimestampUTC         = "utc"
+	TimestampLocal       = "local"
+	TimestampRFC3339Nano = "rfc3339nano"
+)
+
+// ValueDefault, ValueJSON, ValueVerbose and ValueExpand are the values
+// accepted by the ValueFormatKey viper variable
+const (
+	ValueDefault = "default"
+	ValueJSON    = "json"
+	ValueVerbose = "verbose"
+	ValueExpand  = "expand"
 )
 
 // PrefixField is the viper variable to set and get the prefix to use in the text
@@ -47,6 +180,7 @@ const (
 	defShortTimestamp   = false
 	defTimestampFormat  = ""
 	defPrefix           = ""
+	defFormat           = TextFormat
 )
 
 // Log levels:
@@ -65,131 +199,129 @@ type Logger struct {
 
 	mu     sync.Mutex
 	prefix string
+
+	// sniffLevels takes the level of the standard library logger messages from
+	// the level word in the message
+	sniffLevels bool
+
+	// input is read by Ask and AskSecret, inputReader buffers it
+	input       io.Reader
+	inputReader *bufio.Reader
+
+	// levels has the logger level and the level of some prefixes, revert has
+	// the levels to restore after a temporary change with the LevelHandler
+	levels *prefixLevels
+	revert *levelsRevert
+
+	// stackLevel is the level of the least severe entries with a stack trace
+	stackLevel logrus.Level
+
+	// uiOutput is where Output prints the raw messages, if nil they are
+	// printed as log entries. stdoutUI is set if it's the standard output
+	// because no UI output was configured.
+	uiOutput io.Writer
+	stdoutUI bool
+
+	// reloadMu and settings keep the viper variables applied by Reload
+	reloadMu sync.Mutex
+	settings map[string]string
 }
 
 // New creates a new Logger configured from an existing viper instance
 func New(v *viper.Viper) *Logger {
-	logger := &Logger{
-		prefix: v.GetString(PrefixField),
-	}
-	logger.Formatter = &TextFormatter{
-		ForceColors:      v.GetBool(ForceColorsKey),
-		DisableColors:    v.GetBool(DisableColorsKey),
-		DisableTimestamp: v.GetBool(DisableTimestampKey),
-		ShortTimestamp:   v.GetBool(ShortTimestampKey),
-		TimestampFormat:  v.GetString(TimestampFormatKey),
-	}
-	// DisableTimestamp: true, DisableColors: true
-
-	if v.IsSet(OutputKey) {
-		logger.Out = v.Get(OutputKey).(io.Writer)
-	} else if v.IsSet(FilenameKey) {
-		logfilename := v.GetString(FilenameKey)
-		out, err := os.OpenFile(logfilename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
-		if err == nil {
-			logger.Out = out
-		} else {
-			logger.Errorf("Cannot create log file %s. %s", logfilename, err)
-		}
-	}
-
-	if v.IsSet(LevelKey) {
-		logLevel, err := logrus.ParseLevel(v.GetString(LevelKey))
-		if err == nil {
-			logger.Level = logLevel
-		}
-	}
-
-	return logger
+	return NewFromConfig(ConfigFromViper(v))
 }
 
 // NewDefault creates a new Logger configured with defaults values or global
 // viper values if they are defined.
 func NewDefault() *Logger {
-	prefix := defPrefix
-	if viper.IsSet(PrefixField) {
-		prefix = viper.GetString(PrefixField)
-	}
-	logger := &Logger{
-		prefix: prefix,
-	}
+	return New(viper.GetViper())
+}
 
-	forceColors := defForceColors
-	if viper.IsSet(ForceColorsKey) {
-		forceColors = viper.GetBool(ForceColorsKey)
-	}
-	disableColors := defDisableColors
-	if viper.IsSet(DisableColorsKey) {
-		forceColors = viper.GetBool(DisableColorsKey)
-	}
-	disableTimestampKey := defDisableTimestamp
-	if viper.IsSet(DisableTimestampKey) {
-		forceColors = viper.GetBool(DisableTimestampKey)
-	}
-	shortTimestamp := defShortTimestamp
-	if viper.IsSet(ShortTimestampKey) {
-		forceColors = viper.GetBool(ShortTimestampKey)
+// Copy makes a deep copy of this logger
+func (logger *Logger) Copy() *Logger {
+	l := Logger{
+		prefix:      logger.prefix,
+		sniffLevels: logger.sniffLevels,
+		input:       logger.input,
+		uiOutput:    logger.uiOutput,
+		stdoutUI:    logger.stdoutUI,
 	}
-	timestampFormat := defTimestampFormat
-	if viper.IsSet(TimestampFormatKey) {
-		timestampFormat = viper.GetString(TimestampFormatKey)
+	l.Formatter = copyFormatter(logger.formatter())
+	l.Out = logger.output()
+	l.ReportCaller = logger.ReportCaller
+	l.stackLevel = logger.StackTraceLevel()
+	l.addHooks()
+
+	l.levels = newPrefixLevels(logger.GetLevel())
+	for prefix, level := range logger.PrefixLevels() {
+		l.levels.set(prefix, level)
 	}
-	logger.Formatter = &TextFormatter{
-		ForceColors:      forceColors,
-		DisableColors:    disableColors,
-		DisableTimestamp: disableTimestampKey,
-		ShortTimestamp:   shortTimestamp,
-		TimestampFormat:  timestampFormat,
+	l.applyLevels()
+
+	return &l
+}
+
+// addHooks adds the hooks to report the real caller and the stack traces,
+// creating the hooks if the logger does not have them
+func (logger *Logger) addHooks() {
+	if logger.Hooks == nil {
+		logger.Hooks = make(logrus.LevelHooks)
 	}
-	// DisableTimestamp: true, DisableColors: true
-
-	if viper.IsSet(OutputKey) {
-		logger.Out = viper.Get(OutputKey).(io.Writer)
-	} else if viper.IsSet(FilenameKey) {
-		logfilename := viper.GetString(FilenameKey)
-		out, err := os.OpenFile(logfilename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
-		if err == nil {
-			logger.Out = out
-		} else {
-			logger.Errorf("Cannot create log file %s. %s", logfilename, err)
+	logger.AddHook(&callerHook{logger: logger})
+	logger.AddHook(&stackHook{logger: logger})
+}
+
+// copyFormatter returns a copy of the known formatters, any other formatter is
+// shared with the original logger
+func copyFormatter(formatter logrus.Formatter) logrus.Formatter {
+	switch formatter := formatter.(type) {
+	case *TextFormatter:
+		return &TextFormatter{
+			ForceColors:      formatter.ForceColors,
+			DisableColors:    formatter.DisableColors,
+			DisableTimestamp: formatter.DisableTimestamp,
+			ShortTimestamp:   formatter.ShortTimestamp,
+			TimestampFormat:  formatter.TimestampFormat,
+			DisableSorting:   formatter.DisableSorting,
+			AbbreviatePrefix: formatter.AbbreviatePrefix,
+			CallerFunction:   formatter.CallerFunction,
+			Multiline:        formatter.Multiline,
+			Theme:            copyTheme(formatter.Theme),
+			ColorLevel:       formatter.ColorLevel,
+			PrefixColors:     formatter.PrefixColors.copy(),
+			PrefixWidth:      formatter.PrefixWidth,
+			AutoPrefixWidth:  formatter.AutoPrefixWidth,
+			RightAlignLevel:  formatter.RightAlignLevel,
+			FieldsColumn:     formatter.FieldsColumn,
+			TimestampMode:    formatter.TimestampMode,
+			ValueFormat:      formatter.ValueFormat,
+			MaxValueDepth:    formatter.MaxValueDepth,
+			MaxValueLength:   formatter.MaxValueLength,
+			clock:            formatter.clock.copy(),
 		}
-	} else {
-		logger.Out = os.Stderr
-	}
-
-	if viper.IsSet(LevelKey) {
-		logLevel, err := logrus.ParseLevel(viper.GetString(LevelKey))
-		if err == nil {
-			logger.Level = logLevel
+	case *JSONFormatter:
+		return &JSONFormatter{
+			DisableTimestamp: formatter.DisableTimestamp,
+			TimestampFormat:  formatter.TimestampFormat,
 		}
-	} else {
-		logger.Level = defLevel
+	case *LogfmtFormatter:
+		return &LogfmtFormatter{
+			DisableTimestamp: formatter.DisableTimestamp,
+			TimestampFormat:  formatter.TimestampFormat,
+			DisableSorting:   formatter.DisableSorting,
+		}
+	default:
+		return formatter
 	}
-
-	return logger
 }
 
-// Copy makes a deep copy of this logger
-func (logger *Logger) Copy() *Logger {
-	var formatter *TextFormatter
-	formatter = logger.Formatter.(*TextFormatter)
-
-	textFormatter := &TextFormatter{
-		ForceColors:      formatter.ForceColors,
-		DisableColors:    formatter.DisableColors,
-		DisableTimestamp: formatter.DisableTimestamp,
-		ShortTimestamp:   formatter.ShortTimestamp,
-		TimestampFormat:  formatter.TimestampFormat,
-	}
-
-	l := Logger{
-		prefix: logger.prefix,
+func copyTheme(theme *Theme) *Theme {
+	if theme == nil {
+		return nil
 	}
-	l.Formatter = textFormatter
-	l.Out = logger.Out
-	l.Level = logger.Level
-
-	return &l
+	t := *theme
+	return &t
 }
 
 // NewEntryWithPrefix creates a new logrus.Entry with a prefix.
@@ -219,123 +351,123 @@ func (logger *Logger) SetPrefix(prefix string) {
 
 // Debugf redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Debugf(format string, args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Debugf(format, args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Debugf(format, args...)
 }
 
 // Infof redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Infof(format string, args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Infof(format, args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Infof(format, args...)
 }
 
 // Printf redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Printf(format string, args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Printf(format, args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Printf(format, args...)
 }
 
 // Warnf redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Warnf(format string, args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Warnf(format, args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Warnf(format, args...)
 }
 
 // Warningf redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Warningf(format string, args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Warnf(format, args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Warnf(format, args...)
 }
 
 // Errorf redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Errorf(format string, args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Errorf(format, args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Errorf(format, args...)
 }
 
 // Fatalf redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Fatalf(format string, args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Fatalf(format, args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Fatalf(format, args...)
 }
 
 // Panicf redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Panicf(format string, args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Panicf(format, args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Panicf(format, args...)
 }
 
 // Debug redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Debug(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Debug(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Debug(args...)
 }
 
 // Print redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Print(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Info(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Info(args...)
 }
 
 // Warning redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Warning(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Warn(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Warn(args...)
 }
 
 // Fatal redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Fatal(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Fatal(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Fatal(args...)
 }
 
 // Panic redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Panic(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Panic(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Panic(args...)
 }
 
 // Debugln redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Debugln(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Debugln(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Debugln(args...)
 }
 
 // Infoln redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Infoln(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Infoln(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Infoln(args...)
 }
 
 // Println redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Println(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Println(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Println(args...)
 }
 
 // Warnln redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Warnln(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Warnln(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Warnln(args...)
 }
 
 // Warningln redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Warningln(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Warnln(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Warnln(args...)
 }
 
 // Errorln redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Errorln(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Errorln(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Errorln(args...)
 }
 
 // Fatalln redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Fatalln(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Fatalln(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Fatalln(args...)
 }
 
 // Panicln redeclares the logrus method with the same name to use the prefix set
 func (logger *Logger) Panicln(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Panicln(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Panicln(args...)
 }
 
 // Error cannot be exported as they are redefined as in mitchellh/cli.Ui to
 // implement that interface
 func (logger *Logger) error(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Error(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Error(args...)
 }
 
 // Info cannot be exported as they are redefined as in mitchellh/cli.Ui to
 // implement that interface
 func (logger *Logger) info(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Info(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Info(args...)
 }
 
 // Warn cannot be exported as they are redefined as in mitchellh/cli.Ui to
 // implement that interface
 func (logger *Logger) warn(args ...interface{}) {
-	logger.WithField(PrefixField, logger.prefix).Warn(args...)
+	logger.WithField(PrefixField, logger.GetPrefix()).Warn(args...)
 }
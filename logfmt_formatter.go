@@ -0,0 +1,163 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogfmtFormatter formats the logs in logfmt (https://brandur.org/logfmt), one
+// entry per line with the keys time, level, prefix, caller and msg first,
+// followed by the entry fields.
+type LogfmtFormatter struct {
+	// Disable timestamp logging. useful when output is redirected to logging
+	// system that already adds timestamps.
+	DisableTimestamp bool
+
+	// TimestampFormat to use for display when a full timestamp is printed
+	TimestampFormat string
+
+	// The fields are sorted by default for a consistent output. For applications
+	// that log extremely frequently this may not be desired.
+	DisableSorting bool
+}
+
+// Format renders a single log entry
+func (f *LogfmtFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	var b *bytes.Buffer
+	if entry.Buffer != nil {
+		b = entry.Buffer
+	} else {
+		b = &bytes.Buffer{}
+	}
+
+	data := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	prefixFieldClashes(data)
+	if c, ok := data[CallerField]; ok && entry.HasCaller() {
+		data["fields."+CallerField] = c
+	}
+
+	var keys = make([]string, 0, len(data))
+	for k := range data {
+		switch k {
+		case PrefixField, "time", "msg", "level":
+			continue
+		case CallerField:
+			if entry.HasCaller() {
+				continue
+			}
+		}
+		keys = append(keys, k)
+	}
+	if !f.DisableSorting {
+		sort.Strings(keys)
+	}
+
+	timestampFormat := f.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = time.RFC3339
+	}
+
+	if !f.DisableTimestamp {
+		appendLogfmtKeyValue(b, "time", entry.Time.Format(timestampFormat))
+	}
+	appendLogfmtKeyValue(b, "level", entry.Level.String())
+	if prefix, ok := data[PrefixField]; ok && prefix != "" {
+		appendLogfmtKeyValue(b, PrefixField, prefix)
+	}
+	if entry.HasCaller() {
+		appendLogfmtKeyValue(b, CallerField, shortCaller(entry.Caller))
+	}
+	appendLogfmtKeyValue(b, "msg", entry.Message)
+	for _, k := range keys {
+		appendLogfmtKeyValue(b, k, data[k])
+	}
+
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+func appendLogfmtKeyValue(b *bytes.Buffer, key string, value interface{}) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteString(logfmtKey(key))
+	b.WriteByte('=')
+
+	var text string
+	switch value := value.(type) {
+	case string:
+		text = value
+	case error:
+		text = value.Error()
+	case fmt.Stringer:
+		text = value.String()
+	default:
+		text = fmt.Sprint(value)
+	}
+
+	if logfmtNeedsQuoting(text) {
+		appendLogfmtQuoted(b, text)
+	} else {
+		b.WriteString(text)
+	}
+}
+
+// logfmtKey replaces the characters that are not allowed in a logfmt key
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtNeedsQuoting returns true if the value has to be quoted to be parsed
+// as a single logfmt value
+func logfmtNeedsQuoting(text string) bool {
+	if text == "" {
+		return true
+	}
+	for _, ch := range text {
+		if ch <= ' ' || ch == '=' || ch == '"' || ch == '\\' || ch == utf8.RuneError || ch == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+func appendLogfmtQuoted(b *bytes.Buffer, text string) {
+	b.WriteByte('"')
+	for _, ch := range text {
+		switch ch {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(ch)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if ch < ' ' || ch == 0x7f {
+				fmt.Fprintf(b, `\u%04x`, ch)
+			} else {
+				b.WriteRune(ch)
+			}
+		}
+	}
+	b.WriteByte('"')
+}
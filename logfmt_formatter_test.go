@@ -0,0 +1,48 @@
+package log_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func TestLogfmtFormatter(t *testing.T) {
+	var expectedLogMessage string
+	var actualLogMessage string
+
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.FormatKey, log.LogfmtFormat)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.LevelKey, "debug")
+	l := newLogger(&b, v)
+
+	l.Prefix("test").WithFields(logrus.Fields{"path": "/var/log:app_1", "env": "test testing"}).Info("Information")
+	expectedLogMessage = `level=info prefix=test msg=Information env="test testing" path=/var/log:app_1` + "\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	l.Prefix("test").WithFields(logrus.Fields{"err": errors.New(`say "hi"`), "empty": "", "a key": 1}).Error("line 1\nline 2")
+	expectedLogMessage = `level=error prefix=test msg="line 1\nline 2" a_key=1 empty="" err="say \"hi\""` + "\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	l.Warn("Warning")
+	expectedLogMessage = "level=warning msg=Warning\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+}
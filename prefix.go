@@ -0,0 +1,62 @@
+package log
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PrefixSeparator separates the names of a hierarchical prefix, such as
+// "server/auth". Set it to "." for dotted prefixes like "server.auth".
+var PrefixSeparator = "/"
+
+// treeSuffix ends the patterns matching a prefix and all its descendants
+const treeSuffix = "**"
+
+// joinPrefix appends the name to the parent prefix
+func joinPrefix(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	if name == "" {
+		return parent
+	}
+	return parent + PrefixSeparator + name
+}
+
+// abbreviatePrefix shortens every name of a hierarchical prefix to the first
+// letter but the last one, so "server/auth/handler" is "s/a/handler"
+func abbreviatePrefix(prefix string) string {
+	names := strings.Split(prefix, PrefixSeparator)
+	for i, name := range names[:len(names)-1] {
+		if name != "" {
+			names[i] = string([]rune(name)[:1])
+		}
+	}
+	return strings.Join(names, PrefixSeparator)
+}
+
+// SubPrefix creates a new logrus.Entry with the name appended to the logger
+// prefix, like "server/auth" for the logger with prefix "server".
+func (logger *Logger) SubPrefix(name string) *logrus.Entry {
+	return logger.WithField(PrefixField, joinPrefix(logger.GetPrefix(), name))
+}
+
+// SubPrefix returns a new entry with the name appended to the entry prefix, so
+// a component receiving the entry of Prefix("server") can log with the prefix
+// "server/auth"
+func SubPrefix(entry *logrus.Entry, name string) *logrus.Entry {
+	parent, _ := entry.Data[PrefixField].(string)
+	return entry.WithField(PrefixField, joinPrefix(parent, name))
+}
+
+// SetPrefixTreeLevel sets the level for the entries with the given prefix and
+// all the prefixes below it. Use OffLevel to silence all of them.
+func (logger *Logger) SetPrefixTreeLevel(prefix string, level logrus.Level) {
+	logger.SetPrefixLevel(prefix+PrefixSeparator+treeSuffix, level)
+}
+
+// ClearPrefixTreeLevel removes the level set with SetPrefixTreeLevel
+func (logger *Logger) ClearPrefixTreeLevel(prefix string) {
+	logger.ClearPrefixLevel(prefix + PrefixSeparator + treeSuffix)
+}
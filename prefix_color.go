@@ -0,0 +1,245 @@
+package log
+
+import (
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mgutz/ansi"
+)
+
+// DefaultPrefixPalette are the colors given to the prefixes when the text
+// formatter hashes them, like docker-compose does with the service names, if
+// the output has 16 colors. The colors with the hue of a level color in the
+// theme are skipped, so with the default theme a prefix is never red, yellow,
+// green or blue, bright or not.
+var DefaultPrefixPalette = []string{
+	"cyan",
+	"magenta",
+	"cyan+h",
+	"magenta+h",
+	"blue+h",
+	"yellow+h",
+	"green+h",
+	"red+h",
+	"white+b",
+}
+
+// DefaultPrefixPalette256 are the colors given to the hashed prefixes if the
+// output has 256 colors or more. They are orange, lime, spring green, cyan,
+// sky blue, purple, orchid, magenta and pink tones, away from the hues of the
+// level colors of the default theme.
+var DefaultPrefixPalette256 = []string{
+	"208",
+	"118",
+	"48",
+	"51",
+	"39",
+	"135",
+	"171",
+	"201",
+	"205",
+	"159",
+	"219",
+}
+
+// minHueDistance is the minimum distance in degrees between the hue of a
+// prefix color and the hue of a level color with 256 colors or more
+const minHueDistance = 20
+
+// PrefixColors are the colors of the prefixes printed by the text formatter.
+// Colors has the color of some prefixes and, if Hash is true, every other
+// prefix gets a color of Palette, or DefaultPrefixPalette256 or
+// DefaultPrefixPalette for the colors of the output if it's empty, from a hash
+// of the prefix so it is always the same. Like in Theme, the colors are
+// github.com/mgutz/ansi styles.
+type PrefixColors struct {
+	Hash    bool
+	Palette []string
+	Colors  map[string]string
+}
+
+// copy returns a deep copy of the prefix colors
+func (p *PrefixColors) copy() *PrefixColors {
+	if p == nil {
+		return nil
+	}
+	c := &PrefixColors{
+		Hash:    p.Hash,
+		Palette: append([]string(nil), p.Palette...),
+	}
+	if p.Colors != nil {
+		c.Colors = make(map[string]string, len(p.Colors))
+		for prefix, style := range p.Colors {
+			c.Colors[prefix] = style
+		}
+	}
+	return c
+}
+
+// prefixStyle returns the color of the prefix: the color set for the prefix,
+// a color of the palette if the prefixes are hashed or the theme prefix color.
+// The default palette depends on the colors of the output.
+func (f *TextFormatter) prefixStyle(prefix string, colors ColorLevel) string {
+	theme := f.theme()
+	p := f.PrefixColors
+	if p == nil {
+		return theme.Prefix
+	}
+	if style, ok := p.Colors[prefix]; ok {
+		return style
+	}
+	if !p.Hash || prefix == "" {
+		return theme.Prefix
+	}
+
+	palette := p.Palette
+	if len(palette) == 0 {
+		palette = DefaultPrefixPalette
+		if colors >= Color256 {
+			palette = DefaultPrefixPalette256
+		}
+	}
+	palette = withoutLevelColors(palette, theme, colors)
+	if len(palette) == 0 {
+		return theme.Prefix
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(prefix))
+	return palette[h.Sum32()%uint32(len(palette))]
+}
+
+// withoutLevelColors returns the colors of the palette with a different hue
+// than the colors of the levels of the theme. With 16 colors the basic colors
+// are compared, with more colors the hues closer than minHueDistance degrees.
+func withoutLevelColors(palette []string, theme *Theme, colors ColorLevel) []string {
+	levelStyles := []string{theme.Trace, theme.Debug, theme.Info, theme.Warning, theme.Error, theme.Fatal, theme.Panic}
+	if colors >= Color256 {
+		return withoutHues(palette, levelStyles)
+	}
+
+	levels := map[int]bool{}
+	for _, style := range levelStyles {
+		if h, ok := hue(style); ok {
+			levels[h] = true
+		}
+	}
+
+	styles := make([]string, 0, len(palette))
+	for _, style := range palette {
+		if h, ok := hue(style); !ok || !levels[h] {
+			styles = append(styles, style)
+		}
+	}
+	return styles
+}
+
+// withoutHues returns the colors of the palette with a hue angle far from the
+// hue angle of the level colors. The grays have no hue, they are never removed.
+func withoutHues(palette, levelStyles []string) []string {
+	levels := []float64{}
+	for _, style := range levelStyles {
+		if h, ok := hueAngle(style); ok {
+			levels = append(levels, h)
+		}
+	}
+
+	styles := make([]string, 0, len(palette))
+	for _, style := range palette {
+		h, ok := hueAngle(style)
+		near := false
+		for _, level := range levels {
+			if d := math.Abs(h - level); ok && math.Min(d, 360-d) < minHueDistance {
+				near = true
+				break
+			}
+		}
+		if !near {
+			styles = append(styles, style)
+		}
+	}
+	return styles
+}
+
+// hue returns the basic color, from 0 (black) to 7 (white), of the foreground
+// of the style without the bright or bold attributes
+func hue(style string) (int, bool) {
+	if i := strings.Index(style, ":"); i >= 0 {
+		style = style[:i]
+	}
+	color, _ := splitStyle(style)
+	if color == "" {
+		return 0, false
+	}
+
+	if strings.HasPrefix(color, "#") {
+		r, g, b, err := parseHexColor(color)
+		if err != nil {
+			return 0, false
+		}
+		return nearest16(r, g, b) % 8, true
+	}
+	if n, err := strconv.Atoi(color); err == nil {
+		if n >= 16 {
+			r, g, b := rgb256(n)
+			n = nearest16(r, g, b)
+		}
+		return n % 8, true
+	}
+	n, ok := ansi.Colors[color]
+	if !ok || n > 7 {
+		return 0, false
+	}
+	return n, true
+}
+
+// hueAngle returns the hue in degrees of the foreground color of the style as
+// printed with 256 colors or more. The grays have no hue.
+func hueAngle(style string) (float64, bool) {
+	if i := strings.Index(style, ":"); i >= 0 {
+		style = style[:i]
+	}
+	color, attrs := splitStyle(style)
+
+	var r, g, b int
+	if strings.HasPrefix(color, "#") {
+		var err error
+		if r, g, b, err = parseHexColor(color); err != nil {
+			return 0, false
+		}
+	} else if n, err := strconv.Atoi(color); err == nil && n >= 0 && n < 256 {
+		r, g, b = rgb256(n)
+	} else if n, ok := ansi.Colors[color]; ok && n < 8 {
+		if strings.Contains(attrs, "h") {
+			n += 8
+		}
+		r, g, b = rgb256(n)
+	} else {
+		return 0, false
+	}
+
+	max := math.Max(float64(r), math.Max(float64(g), float64(b)))
+	min := math.Min(float64(r), math.Min(float64(g), float64(b)))
+	d := max - min
+	// the colors with low saturation look gray
+	if max == 0 || d/max < 0.25 {
+		return 0, false
+	}
+
+	var h float64
+	switch max {
+	case float64(r):
+		h = math.Mod((float64(g)-float64(b))/d, 6)
+	case float64(g):
+		h = (float64(b)-float64(r))/d + 2
+	default:
+		h = (float64(r)-float64(g))/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, true
+}
@@ -0,0 +1,143 @@
+package log_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/viper"
+)
+
+func TestHashPrefixColors(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.ForceColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.HashPrefixColorsKey, true)
+	v.Set(log.PrefixPaletteKey, []string{"cyan", "magenta", "blue+h"})
+	l := newLogger(&b, v)
+
+	colorOf := func(l *log.Logger, prefix string) string {
+		b.Reset()
+		l.Prefix(prefix).Info("Hello")
+		line := strings.TrimPrefix(b.String(), ansi.ColorCode("green")+"INFO "+ansi.Reset+" ")
+		return strings.TrimSuffix(line, prefix+":"+ansi.Reset+" Hello\n")
+	}
+
+	prefixes := []string{"api", "db", "cache", "worker", "scheduler", "auth"}
+	used := map[string]bool{}
+	for _, prefix := range prefixes {
+		color := colorOf(l, prefix)
+		switch color {
+		case ansi.ColorCode("cyan"), ansi.ColorCode("magenta"), ansi.ColorCode("blue+h"):
+			used[color] = true
+		default:
+			t.Errorf("Expected a color of the palette for %q, but got %q", prefix, color)
+		}
+		if again := colorOf(l.Copy(), prefix); again != color {
+			t.Errorf("Expected the same color for %q, but got %q and %q", prefix, color, again)
+		}
+	}
+	if len(used) < 2 {
+		t.Errorf("Expected the prefixes to have different colors, but got %v", used)
+	}
+}
+
+func TestPrefixColors(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.ForceColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.HashPrefixColorsKey, true)
+	v.Set(log.PrefixPaletteKey, []string{"green", "magenta"})
+	v.Set(log.PrefixColorsKey, map[string]string{"db": "yellow+b"})
+	l := newLogger(&b, v)
+
+	// green is the info level color, so it's not in the palette
+	l.Prefix("api").Info("Hello")
+	expectedLogMessage := ansi.ColorCode("green") + "INFO " + ansi.Reset + " " + ansi.ColorCode("magenta") + "api:" + ansi.Reset + " Hello\n"
+	actualLogMessage := b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	l.Prefix("db").Info("Hello")
+	expectedLogMessage = ansi.ColorCode("green") + "INFO " + ansi.Reset + " " + ansi.ColorCode("yellow+b") + "db:" + ansi.Reset + " Hello\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	v.Set(log.PrefixColorsKey, map[string]string{"db": "pink"})
+	if err := log.Validate(v); err == nil || !strings.Contains(err.Error(), log.PrefixColorsKey) {
+		t.Errorf("Expected an error for %s, but got %v", log.PrefixColorsKey, err)
+	}
+}
+
+func TestDefaultPrefixPalette(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.ForceColorsKey, true)
+	v.Set(log.ColorLevelKey, "16")
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.HashPrefixColorsKey, true)
+	l := newLogger(&b, v)
+
+	levelColors := map[string]bool{}
+	for _, style := range []string{"red", "green", "yellow", "blue"} {
+		levelColors[ansi.ColorCode(style)] = true
+		levelColors[ansi.ColorCode(style+"+h")] = true
+		levelColors[ansi.ColorCode(style+"+b")] = true
+	}
+	for i := 0; i < 50; i++ {
+		b.Reset()
+		prefix := fmt.Sprintf("prefix%d", i)
+		l.Prefix(prefix).Info("Hello")
+		line := strings.TrimPrefix(b.String(), ansi.ColorCode("green")+"INFO "+ansi.Reset+" ")
+		color := strings.TrimSuffix(line, prefix+":"+ansi.Reset+" Hello\n")
+		if levelColors[color] {
+			t.Errorf("Expected a color different to the levels for %q, but got %q", prefix, color)
+		}
+	}
+}
+
+func TestDefaultPrefixPalette256(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.ForceColorsKey, true)
+	v.Set(log.ColorLevelKey, "256")
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.HashPrefixColorsKey, true)
+	l := newLogger(&b, v)
+
+	// the prefixes of a dozen components get many colors, none of a level
+	colors := map[string]bool{}
+	for i := 0; i < 200; i++ {
+		b.Reset()
+		prefix := fmt.Sprintf("prefix%d", i)
+		l.Prefix(prefix).Info("Hello")
+		line := b.String()
+		start := strings.Index(line, ansi.Reset+" ")
+		end := strings.Index(line, prefix+":")
+		if start < 0 || end < 0 {
+			t.Fatalf("Expected a colored prefix, but got %q", line)
+		}
+		color := line[start+len(ansi.Reset)+1 : end]
+		if !strings.Contains(color, "38;5;") {
+			t.Errorf("Expected a 256 colors prefix for %q, but got %q", prefix, color)
+		}
+		colors[color] = true
+	}
+	if len(colors) < 8 {
+		t.Errorf("Expected at least 8 prefix colors, but got %d: %v", len(colors), colors)
+	}
+}
@@ -0,0 +1,87 @@
+package log_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func TestSubPrefix(t *testing.T) {
+	var expectedLogMessage string
+	var actualLogMessage string
+
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.LevelKey, "debug")
+	l := newLogger(&b, v)
+	l.SetPrefix("server")
+
+	l.SubPrefix("auth").Info("Login")
+	expectedLogMessage = "INFO  server/auth: Login\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	entry := l.Prefix("server")
+	log.SubPrefix(log.SubPrefix(entry, "auth"), "handler").WithField("user", "john").Warn("Denied")
+	expectedLogMessage = "WARN  server/auth/handler: Denied user=john\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	l.Formatter.(*log.TextFormatter).AbbreviatePrefix = true
+	log.SubPrefix(log.SubPrefix(entry, "auth"), "handler").Info("Abbreviated")
+	expectedLogMessage = "INFO  s/a/handler: Abbreviated\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+}
+
+func TestPrefixTreeLevel(t *testing.T) {
+	var expectedLogMessage string
+	var actualLogMessage string
+
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.FormatKey, log.LogfmtFormat)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.LevelKey, "info")
+	l := newLogger(&b, v)
+
+	l.SetPrefixTreeLevel("server", logrus.DebugLevel)
+	l.SetPrefixTreeLevel("server/db", log.OffLevel)
+
+	l.Prefix("server").Debug("Started")
+	l.Prefix("server/auth").Debug("Login")
+	l.Prefix("server/db").Error("Hidden")
+	l.Prefix("server/db/pool").Error("Hidden")
+	l.Prefix("serverless").Debug("Hidden")
+	expectedLogMessage = "level=debug prefix=server msg=Started\n" +
+		"level=debug prefix=server/auth msg=Login\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	l.ClearPrefixTreeLevel("server/db")
+	l.Prefix("server/db").Debug("Query")
+	expectedLogMessage = "level=debug prefix=server/db msg=Query\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+}
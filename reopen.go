@@ -0,0 +1,104 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Reopen opens again the log file the logger is writing to. It's used after an
+// external tool, such as logrotate, moved the file. The new file is swapped
+// with the old one under a lock so the entries written concurrently are not
+// lost or interleaved.
+func (logger *Logger) Reopen() error {
+	switch out := logger.output().(type) {
+	case *RotatingFile:
+		return out.Reopen()
+	case *os.File:
+		if out == os.Stdout || out == os.Stderr {
+			return nil
+		}
+		file, err := os.OpenFile(out.Name(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		logger.SetOutput(file)
+		return out.Close()
+	default:
+		return fmt.Errorf("cannot reopen the log output, it's not a file")
+	}
+}
+
+// ReopenOnSignal reopens the log file every time the process receives one of
+// the given signals, or SIGHUP if none is given. The returned function stops
+// listening for the signals.
+//
+// The loggers created from a configuration with ReopenKey share one handler
+// per log file, stopped when the file is closed.
+func (logger *Logger) ReopenOnSignal(sig ...os.Signal) (stop func()) {
+	return onSignal(sig, func() {
+		if err := logger.Reopen(); err != nil {
+			logger.Errorf("Cannot reopen log file. %s", err)
+		}
+	})
+}
+
+// onSignal calls fn every time the process receives one of the signals, or
+// SIGHUP if none is given, until stop is called
+func onSignal(sig []os.Signal, fn func()) (stop func()) {
+	if len(sig) == 0 {
+		sig = []os.Signal{syscall.SIGHUP}
+	}
+
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, sig...)
+
+	go func() {
+		for {
+			select {
+			case <-c:
+				fn()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
+
+// reopenOnSignal reopens the file every time the process receives SIGHUP. The
+// file has only one handler, no matter how many loggers share it.
+func (r *RotatingFile) reopenOnSignal() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopReopen != nil {
+		return
+	}
+	r.stopReopen = onSignal(nil, func() {
+		if err := r.Reopen(); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot reopen log file %s. %s\n", r.Filename, err)
+		}
+	})
+}
+
+// SetOutput sets the logger output
+func (logger *Logger) SetOutput(out io.Writer) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.Logger.SetOutput(out)
+}
+
+// output returns the logger output
+func (logger *Logger) output() io.Writer {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	return logger.Out
+}
@@ -0,0 +1,82 @@
+package log_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/johandry/log"
+	"github.com/spf13/viper"
+)
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.log")
+	moved := filepath.Join(dir, "test.log.1")
+
+	v := viper.New()
+	v.Set(log.FilenameKey, filename)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.LevelKey, "debug")
+	l := log.New(v)
+
+	l.Info("before")
+	if err := os.Rename(filename, moved); err != nil {
+		t.Fatalf("Cannot move log file. %s", err)
+	}
+	l.Info("moved")
+	if err := l.Reopen(); err != nil {
+		t.Fatalf("Cannot reopen log file. %s", err)
+	}
+	l.Info("after")
+
+	content, _ := os.ReadFile(moved)
+	if !strings.Contains(string(content), "before") || !strings.Contains(string(content), "moved") {
+		t.Errorf("Expected the moved file to have the entries before reopen, but got '%s'", content)
+	}
+	content, _ = os.ReadFile(filename)
+	if strings.Contains(string(content), "before") || !strings.Contains(string(content), "after") {
+		t.Errorf("Expected the new file to have only the entries after reopen, but got '%s'", content)
+	}
+}
+
+func TestReopenOnSignal(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.log")
+	moved := filepath.Join(dir, "test.log.1")
+
+	v := viper.New()
+	v.Set(log.FilenameKey, filename)
+	v.Set(log.ReopenKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.DisableColorsKey, true)
+	l := log.New(v)
+	other := log.New(v)
+	defer l.Out.(*log.RotatingFile).Close()
+
+	l.Info("before")
+	if err := os.Rename(filename, moved); err != nil {
+		t.Fatalf("Cannot move log file. %s", err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("Cannot send SIGHUP. %s", err)
+	}
+	for i := 0; i < 100 && !fileExists(filename); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	other.Info("after")
+
+	content, _ := os.ReadFile(filename)
+	if strings.Contains(string(content), "before") || !strings.Contains(string(content), "after") {
+		t.Errorf("Expected the new file to have only the entries after reopen, but got '%s'", content)
+	}
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
@@ -0,0 +1,487 @@
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the layout used in the name of the rotated files
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+const megabyte = 1024 * 1024
+
+var (
+	rotatingFilesMu sync.Mutex
+	rotatingFiles   = map[string]*RotatingFile{}
+)
+
+// RotatingFile is an io.WriteCloser that writes to the file Filename and
+// rotates it when it reaches MaxSize megabytes or, if Daily is set, when the
+// day changes. The rotated files are renamed to name-timestamp.ext, optionally
+// compressed with gzip and removed when they are older than MaxAge days or
+// there are more than MaxBackups of them.
+//
+// A RotatingFile is safe for concurrent use, all the loggers writing to the
+// same file should share the same RotatingFile. The loggers created from a
+// configuration share the RotatingFile opened for the same filename until it's
+// closed, and fail to open it with different rotation settings.
+//
+// The rotated files are compressed and the old ones removed in the background,
+// one rotation at a time. Close waits for them.
+type RotatingFile struct {
+	// Filename is the file to write logs to
+	Filename string
+
+	// MaxSize is the maximum size in megabytes of the log file before it gets
+	// rotated. Zero means no size limit.
+	MaxSize int
+
+	// MaxAge is the maximum number of days to retain the rotated files. Zero
+	// means to not remove them based on age.
+	MaxAge int
+
+	// MaxBackups is the maximum number of rotated files to retain. Zero means to
+	// retain all of them.
+	MaxBackups int
+
+	// Daily rotates the log file when the first entry of a new day is written
+	Daily bool
+
+	// Compress the rotated files with gzip
+	Compress bool
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	// cleanupJobs are the rotated files to compress and clean up, one at a
+	// time by a single worker running while there are jobs
+	cleanupMu   sync.Mutex
+	cleanupJobs []cleanupJob
+	cleaning    bool
+	cleanupDone sync.WaitGroup
+
+	// stopReopen stops reopening the file on SIGHUP
+	stopReopen func()
+}
+
+// sharedRotatingFile returns the RotatingFile already opened for the same
+// filename, or opens a new one with the given settings. It fails if the file
+// is already opened with different settings.
+func sharedRotatingFile(r *RotatingFile) (*RotatingFile, error) {
+	return openRotatingFile(r, false)
+}
+
+// updateRotatingFile returns the RotatingFile already opened for the same
+// filename with the settings of r, applied to every logger sharing it, or opens
+// a new one with the given settings
+func updateRotatingFile(r *RotatingFile) (*RotatingFile, error) {
+	return openRotatingFile(r, true)
+}
+
+func openRotatingFile(r *RotatingFile, update bool) (*RotatingFile, error) {
+	abs, err := filepath.Abs(r.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	rotatingFilesMu.Lock()
+	defer rotatingFilesMu.Unlock()
+
+	if shared, ok := rotatingFiles[abs]; ok {
+		shared.mu.Lock()
+		defer shared.mu.Unlock()
+		if update {
+			shared.MaxSize = r.MaxSize
+			shared.MaxAge = r.MaxAge
+			shared.MaxBackups = r.MaxBackups
+			shared.Daily = r.Daily
+			shared.Compress = r.Compress
+		}
+		if !shared.sameSettings(r) {
+			return nil, fmt.Errorf("log file %s is already open with different rotation settings", r.Filename)
+		}
+		return shared, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	rotatingFiles[abs] = r
+
+	return r, nil
+}
+
+// shared returns true if the file is the one shared by the loggers created
+// from a configuration
+func (r *RotatingFile) shared() bool {
+	abs, err := filepath.Abs(r.Filename)
+	if err != nil {
+		return false
+	}
+
+	rotatingFilesMu.Lock()
+	defer rotatingFilesMu.Unlock()
+	return rotatingFiles[abs] == r
+}
+
+// sameSettings returns true if both files have the same rotation settings
+func (r *RotatingFile) sameSettings(other *RotatingFile) bool {
+	return r.MaxSize == other.MaxSize &&
+		r.MaxAge == other.MaxAge &&
+		r.MaxBackups == other.MaxBackups &&
+		r.Daily == other.Daily &&
+		r.Compress == other.Compress
+}
+
+// Write implements io.Writer. It rotates the file if writing p would exceed
+// MaxSize or if the day changed and Daily is set. If the rotation fails the
+// entry is still written to the log file and the error is returned.
+func (r *RotatingFile) Write(p []byte) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	var rotateErr error
+	if r.shouldRotate(int64(len(p))) {
+		rotateErr = r.rotate()
+		if r.file == nil {
+			return 0, rotateErr
+		}
+	}
+
+	n, err = r.file.Write(p)
+	r.size += int64(n)
+	if err == nil {
+		err = rotateErr
+	}
+
+	return n, err
+}
+
+// Close implements io.Closer, and closes the current log file once the
+// rotated files are compressed. The loggers created later from a configuration
+// open the file again.
+func (r *RotatingFile) Close() error {
+	if abs, err := filepath.Abs(r.Filename); err == nil {
+		rotatingFilesMu.Lock()
+		if rotatingFiles[abs] == r {
+			delete(rotatingFiles, abs)
+		}
+		rotatingFilesMu.Unlock()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopReopen != nil {
+		r.stopReopen()
+		r.stopReopen = nil
+	}
+	r.cleanupDone.Wait()
+	return r.close()
+}
+
+// Rotate closes the current log file, moves it aside and opens a new one
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rotate()
+}
+
+func (r *RotatingFile) shouldRotate(length int64) bool {
+	if r.MaxSize > 0 && r.size > 0 && r.size+length > int64(r.MaxSize)*megabyte {
+		return true
+	}
+	if r.Daily {
+		y1, m1, d1 := r.openedAt.Date()
+		y2, m2, d2 := time.Now().Date()
+		return y1 != y2 || m1 != m2 || d1 != d2
+	}
+	return false
+}
+
+// Reopen opens the log file again, to be used when an external tool such as
+// logrotate moved it. The new file is opened before closing the old one so no
+// entry is lost.
+func (r *RotatingFile) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.open()
+}
+
+// open opens the log file, replacing and closing the current one if any
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.Filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	if r.file != nil {
+		r.file.Close()
+	}
+	r.file = file
+	r.size = info.Size()
+	r.openedAt = time.Now()
+	if r.Daily && r.size > 0 {
+		r.openedAt = info.ModTime()
+	}
+
+	return nil
+}
+
+func (r *RotatingFile) close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// rotate moves the log file aside and opens a new one. The log file is opened
+// again even if it cannot be moved, so the entries are not lost. The rotated
+// file is compressed and the old rotated files removed in the background.
+func (r *RotatingFile) rotate() error {
+	rotateErr := r.close()
+
+	var backup string
+	if _, err := os.Stat(r.Filename); err == nil && rotateErr == nil {
+		backup = r.backupName(time.Now())
+		if err := os.Rename(r.Filename, backup); err != nil {
+			backup, rotateErr = "", fmt.Errorf("cannot rotate log file %s. %s", r.Filename, err)
+		}
+	}
+
+	if err := r.open(); err != nil {
+		return err
+	}
+	if rotateErr != nil {
+		return rotateErr
+	}
+
+	job := cleanupJob{maxBackups: r.MaxBackups, maxAge: r.MaxAge}
+	if r.Compress {
+		job.compress = backup
+	}
+	r.cleanup(job)
+
+	return nil
+}
+
+// cleanupJob is the work to do in the background after a rotation
+type cleanupJob struct {
+	// compress is the rotated file to compress, if any
+	compress   string
+	maxBackups int
+	maxAge     int
+}
+
+// cleanup queues the job for the cleanup worker, starting it if it's not
+// running. It's called with the lock held, so Close is not waiting for the
+// worker.
+func (r *RotatingFile) cleanup(job cleanupJob) {
+	r.cleanupMu.Lock()
+	defer r.cleanupMu.Unlock()
+
+	r.cleanupJobs = append(r.cleanupJobs, job)
+	if r.cleaning {
+		return
+	}
+	r.cleaning = true
+	r.cleanupDone.Add(1)
+	go r.runCleanup()
+}
+
+// runCleanup does the queued jobs in order until there are no more, so the
+// rotated files are never compressed and removed at the same time
+func (r *RotatingFile) runCleanup() {
+	defer r.cleanupDone.Done()
+
+	for {
+		r.cleanupMu.Lock()
+		if len(r.cleanupJobs) == 0 {
+			r.cleaning = false
+			r.cleanupMu.Unlock()
+			return
+		}
+		job := r.cleanupJobs[0]
+		r.cleanupJobs = r.cleanupJobs[1:]
+		r.cleanupMu.Unlock()
+
+		if job.compress != "" {
+			if err := compressFile(job.compress); err != nil {
+				fmt.Fprintf(os.Stderr, "Cannot compress rotated log file %s. %s\n", job.compress, err)
+				continue
+			}
+		}
+		if err := r.removeBackups(job.maxBackups, job.maxAge); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot remove rotated log files of %s. %s\n", r.Filename, err)
+		}
+	}
+}
+
+// backupName returns an unused name for a rotated file created at t
+func (r *RotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := r.nameParts()
+	name := filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+	for i := 1; fileExists(name) || fileExists(name+".gz"); i++ {
+		name = filepath.Join(dir, fmt.Sprintf("%s%s.%d%s", prefix, t.Format(backupTimeFormat), i, ext))
+	}
+	return name
+}
+
+// nameParts returns the directory, the prefix and the extension used to build
+// the name of the rotated files
+func (r *RotatingFile) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(r.Filename)
+	base := filepath.Base(r.Filename)
+	ext = filepath.Ext(base)
+	prefix = strings.TrimSuffix(base, ext) + "-"
+	return dir, prefix, ext
+}
+
+// backupFile is a rotated file, name is the file before compressing it. Both
+// files exist while it's being compressed.
+type backupFile struct {
+	name      string
+	timestamp time.Time
+}
+
+// backups returns the rotated files, newest first. A file and its compressed
+// copy are the same backup.
+func (r *RotatingFile) backups() ([]backupFile, error) {
+	dir, prefix, ext := r.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []backupFile{}
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimPrefix(name, prefix)
+		ts = strings.TrimSuffix(ts, ".gz")
+		if !strings.HasSuffix(ts, ext) {
+			continue
+		}
+		ts = strings.TrimSuffix(ts, ext)
+		if len(ts) < len(backupTimeFormat) {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, ts[:len(backupTimeFormat)], time.Local)
+		if err != nil {
+			continue
+		}
+		name = strings.TrimSuffix(name, ".gz")
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		backups = append(backups, backupFile{name: filepath.Join(dir, name), timestamp: t})
+	}
+
+	sort.SliceStable(backups, func(i, j int) bool {
+		if backups[i].timestamp.Equal(backups[j].timestamp) {
+			return backups[i].name > backups[j].name
+		}
+		return backups[i].timestamp.After(backups[j].timestamp)
+	})
+
+	return backups, nil
+}
+
+// removeBackups removes the rotated files exceeding maxBackups or maxAge
+// days. It's called by the cleanup worker without the lock, so it does not read
+// the settings.
+func (r *RotatingFile) removeBackups(maxBackups, maxAge int) error {
+	if maxBackups <= 0 && maxAge <= 0 {
+		return nil
+	}
+
+	backups, err := r.backups()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-time.Duration(maxAge) * 24 * time.Hour)
+	for i, b := range backups {
+		if (maxBackups > 0 && i >= maxBackups) || (maxAge > 0 && b.timestamp.Before(cutoff)) {
+			for _, name := range []string{b.name, b.name + ".gz"} {
+				if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// compressFile compresses the file to name.gz and removes it. A file already
+// removed by the cleanup is not an error.
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(name)
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
@@ -0,0 +1,124 @@
+package log_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/spf13/viper"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.log")
+
+	v := viper.New()
+	v.Set(log.FilenameKey, filename)
+	v.Set(log.MaxSizeKey, 1)
+	v.Set(log.MaxBackupsKey, 1)
+	v.Set(log.CompressKey, true)
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.LevelKey, "debug")
+
+	l := log.New(v)
+	c := l.Copy()
+	if l.Out != c.Out {
+		t.Errorf("Expected the copy to share the same output")
+	}
+	if other := log.New(v); other.Out != l.Out {
+		t.Errorf("Expected a logger to the same file to share the same output")
+	}
+	conflicting := viper.New()
+	conflicting.Set(log.FilenameKey, filename)
+	conflicting.Set(log.MaxSizeKey, 2)
+	if other := log.New(conflicting); other.Out == l.Out {
+		t.Errorf("Expected a logger to the same file with other settings to fail opening it")
+	}
+
+	message := strings.Repeat("x", 600*1024)
+	l.Info(message)
+	c.Info(message)
+	l.Info(message)
+
+	// the rotated files are compressed in the background
+	if err := l.Out.(*log.RotatingFile).Close(); err != nil {
+		t.Fatalf("Cannot close log file %s. %s", filename, err)
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "test-*.log.gz"))
+	if len(backups) != 1 {
+		t.Errorf("Expected 1 compressed backup, but got %d: %v", len(backups), backups)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != 2 {
+		t.Errorf("Expected the log file and 1 backup, but got %d: %v", len(files), files)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf("Cannot stat log file %s. %s", filename, err)
+	}
+	if info.Size() > 1024*1024 {
+		t.Errorf("Expected log file size to be less than 1MB, but got %d", info.Size())
+	}
+}
+
+func TestRotatingFileRotate(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.log")
+
+	r := &log.RotatingFile{Filename: filename}
+	defer r.Close()
+
+	if _, err := r.Write([]byte("first\n")); err != nil {
+		t.Fatalf("Cannot write to rotating file. %s", err)
+	}
+	if err := r.Rotate(); err != nil {
+		t.Fatalf("Cannot rotate file. %s", err)
+	}
+	if _, err := r.Write([]byte("second\n")); err != nil {
+		t.Fatalf("Cannot write to rotating file. %s", err)
+	}
+
+	content, _ := os.ReadFile(filename)
+	if string(content) != "second\n" {
+		t.Errorf("Expected 'second', but got '%s'", content)
+	}
+	backups, _ := filepath.Glob(filepath.Join(dir, "test-*.log"))
+	if len(backups) != 1 {
+		t.Fatalf("Expected 1 backup, but got %d: %v", len(backups), backups)
+	}
+	content, _ = os.ReadFile(backups[0])
+	if string(content) != "first\n" {
+		t.Errorf("Expected 'first', but got '%s'", content)
+	}
+}
+
+func TestRotatingFileCompressBackups(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.log")
+
+	r := &log.RotatingFile{Filename: filename, MaxBackups: 2, Compress: true}
+	for i := 0; i < 5; i++ {
+		if _, err := r.Write([]byte(strings.Repeat("x", 64*1024) + "\n")); err != nil {
+			t.Fatalf("Cannot write to rotating file. %s", err)
+		}
+		if err := r.Rotate(); err != nil {
+			t.Fatalf("Cannot rotate file. %s", err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Cannot close log file %s. %s", filename, err)
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "test-*.log.gz"))
+	if len(backups) != 2 {
+		t.Errorf("Expected 2 compressed backups, but got %d: %v", len(backups), backups)
+	}
+	uncompressed, _ := filepath.Glob(filepath.Join(dir, "test-*.log"))
+	if len(uncompressed) != 0 {
+		t.Errorf("Expected no uncompressed backups, but got %v", uncompressed)
+	}
+}
@@ -0,0 +1,146 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// SlogHandler is a slog.Handler that prints the records with a Logger, so
+// they are formatted like any other entry, with the prefix and colors.
+//
+// The groups created with WithGroup are appended to the prefix as a
+// hierarchical prefix, like "server/auth", the groups in the attributes are
+// printed as dotted keys and an attribute with the key PrefixField replaces the
+// logger prefix the groups are appended to.
+type SlogHandler struct {
+	logger *Logger
+	fields logrus.Fields
+	prefix string
+	groups []string
+}
+
+// NewSlogHandler creates a slog.Handler with a new Logger configured from an
+// existing viper instance
+func NewSlogHandler(v *viper.Viper) *SlogHandler {
+	return New(v).SlogHandler()
+}
+
+// SlogHandler returns a slog.Handler that prints the records with this logger
+func (logger *Logger) SlogHandler() *SlogHandler {
+	return &SlogHandler{
+		logger: logger,
+		fields: logrus.Fields{},
+	}
+}
+
+// Enabled reports whether the logger prints records at the given level
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.IsLevelEnabled(logrusLevel(level))
+}
+
+// Handle prints the record
+func (h *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := make(logrus.Fields, len(h.fields)+r.NumAttrs()+1)
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	prefix := h.prefix
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(fields, &prefix, "", a)
+		return true
+	})
+	if len(prefix) == 0 {
+		prefix = h.logger.GetPrefix()
+	}
+	for _, group := range h.groups {
+		prefix = joinPrefix(prefix, group)
+	}
+	fields[PrefixField] = prefix
+
+	entry := h.logger.WithFields(fields)
+	if !r.Time.IsZero() {
+		entry = entry.WithTime(r.Time)
+	}
+	entry.Log(logrusLevel(r.Level), r.Message)
+
+	return nil
+}
+
+// WithAttrs returns a new handler with the attributes resolved once and added
+// to every record
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := h.clone()
+	for _, a := range attrs {
+		addSlogAttr(h2.fields, &h2.prefix, "", a)
+	}
+	return h2
+}
+
+// WithGroup returns a new handler with the group name appended to the prefix
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := h.clone()
+	h2.groups = append(h2.groups[:len(h2.groups):len(h2.groups)], name)
+	return h2
+}
+
+func (h *SlogHandler) clone() *SlogHandler {
+	fields := make(logrus.Fields, len(h.fields))
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	return &SlogHandler{
+		logger: h.logger,
+		fields: fields,
+		prefix: h.prefix,
+		groups: h.groups,
+	}
+}
+
+// addSlogAttr adds the attribute to the fields, with the groups as dotted keys
+func addSlogAttr(fields logrus.Fields, prefix *string, group string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			group = group + a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			addSlogAttr(fields, prefix, group, ga)
+		}
+		return
+	}
+
+	if group == "" && a.Key == PrefixField {
+		*prefix = a.Value.String()
+		return
+	}
+	fields[group+a.Key] = a.Value.Any()
+}
+
+// logrusLevel returns the logrus level of a slog level
+func logrusLevel(level slog.Level) logrus.Level {
+	switch {
+	case level < slog.LevelDebug:
+		return logrus.TraceLevel
+	case level < slog.LevelInfo:
+		return logrus.DebugLevel
+	case level < slog.LevelWarn:
+		return logrus.InfoLevel
+	case level < slog.LevelError:
+		return logrus.WarnLevel
+	default:
+		return logrus.ErrorLevel
+	}
+}
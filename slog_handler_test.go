@@ -0,0 +1,76 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/spf13/viper"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var expectedLogMessage string
+	var actualLogMessage string
+
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.LevelKey, "info")
+	l := newLogger(&b, v)
+	l.SetPrefix("main")
+
+	logger := slog.New(l.SlogHandler())
+
+	logger.Debug("Debug")
+	if b.Len() != 0 {
+		t.Errorf("Expected no debug message, but got '%s'", b.String())
+	}
+
+	logger.Info("Information", "key", "value")
+	expectedLogMessage = "INFO  main: Information key=value\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	logger.With("env", "test").WithGroup("db").Warn("Warning", slog.Group("req", "id", 1, slog.Group("user", "name", "john")))
+	expectedLogMessage = "WARN  main/db: Warning env=test req.id=1 req.user.name=john\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	logger.With(log.PrefixField, "http").Error("Error", "status", 500)
+	expectedLogMessage = "ERROR http: Error status=500\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	logger.With(log.PrefixField, "http").WithGroup("api").WithGroup("v1").Info("Request")
+	expectedLogMessage = "INFO  http/api/v1: Request\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+}
+
+func TestNewSlogHandler(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+	v.Set(log.OutputKey, &b)
+	v.Set(log.LevelKey, "debug")
+
+	h := log.NewSlogHandler(v)
+	if !h.Enabled(context.Background(), slog.LevelDebug) {
+		t.Errorf("Expected debug level to be enabled")
+	}
+}
@@ -0,0 +1,156 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StackField is the key of the stack trace of the entries at or above the
+// stack trace level
+const StackField = "stack"
+
+// maximumStackDepth is the number of frames printed in a stack trace
+const maximumStackDepth = 64
+
+// stackHook adds the stack trace to the entries at or above the stack trace
+// level of the logger. The trace is taken from the errors in the entry if any
+// of them has one, otherwise it is the stack of the code printing the entry.
+type stackHook struct {
+	logger *Logger
+}
+
+func (h *stackHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *stackHook) Fire(entry *logrus.Entry) error {
+	level := h.logger.StackTraceLevel()
+	if level == OffLevel || entry.Level > level || !h.logger.entryEnabled(entry) {
+		return nil
+	}
+	if _, ok := entry.Data[StackField]; ok {
+		return nil
+	}
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var traces []string
+	for _, k := range keys {
+		if err, ok := entry.Data[k].(error); ok {
+			traces = append(traces, errorStackTraces(err)...)
+		}
+	}
+	if len(traces) == 0 {
+		pcs := make([]uintptr, maximumStackDepth)
+		depth := runtime.Callers(2, pcs)
+		traces = append(traces, stackTrace(pcs[:depth], true))
+	}
+	entry.Data[StackField] = strings.Join(traces, "\n")
+
+	return nil
+}
+
+// stackTrace returns the function, file and line of every frame, one per line
+// like the Go panics, skipping the runtime frames and the logging frames at
+// the top if skipLogging is true
+func stackTrace(pcs []uintptr, skipLogging bool) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if skipLogging && isLoggingFrame(frame.Function) {
+			if !more {
+				break
+			}
+			continue
+		}
+		skipLogging = false
+		if frame.Function != "" && !strings.HasPrefix(frame.Function, "runtime.") {
+			if b.Len() > 0 {
+				b.WriteByte('\n')
+			}
+			fmt.Fprintf(&b, "%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// errorStackTraces returns the stack trace of every error in the chain of err
+// that has one, following errors.Unwrap and errors.Join. Every trace starts
+// with the error message.
+func errorStackTraces(err error) []string {
+	var traces []string
+	seen := map[uintptr]bool{}
+
+	var walk func(err error)
+	walk = func(err error) {
+		if err == nil {
+			return
+		}
+		if pcs := errorStack(err); len(pcs) != 0 && !seen[pcs[0]] {
+			// an error embedding the wrapped error has the same trace
+			seen[pcs[0]] = true
+			traces = append(traces, err.Error()+"\n"+stackTrace(pcs, false))
+		}
+		switch e := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+		default:
+			walk(errors.Unwrap(err))
+		}
+	}
+	walk(err)
+
+	return traces
+}
+
+// errorStack returns the program counters of an error with a StackTrace
+// method, such as the errors of github.com/pkg/errors, or nil if it has none.
+// The method should return a slice of program counters, like
+// errors.StackTrace of pkg/errors.
+func errorStack(err error) []uintptr {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil
+	}
+	trace := method.Call(nil)[0]
+	if trace.Kind() != reflect.Slice || trace.Type().Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	pcs := make([]uintptr, trace.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(trace.Index(i).Uint())
+	}
+	return pcs
+}
+
+// SetStackTraceLevel sets the level of the least severe entries printed with
+// a stack trace. Use OffLevel to print no stack traces.
+func (logger *Logger) SetStackTraceLevel(level logrus.Level) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.stackLevel = level
+}
+
+// StackTraceLevel returns the level of the least severe entries printed with a
+// stack trace
+func (logger *Logger) StackTraceLevel() logrus.Level {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	return logger.stackLevel
+}
@@ -0,0 +1,130 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/spf13/viper"
+)
+
+// frame and stackTrace are like the types of github.com/pkg/errors
+type frame uintptr
+type stackTrace []frame
+
+type tracedError struct {
+	msg   string
+	stack []uintptr
+}
+
+func newTracedError(msg string) error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	return &tracedError{msg: msg, stack: pcs[:n]}
+}
+
+func (e *tracedError) Error() string { return e.msg }
+
+func (e *tracedError) StackTrace() stackTrace {
+	trace := make(stackTrace, len(e.stack))
+	for i, pc := range e.stack {
+		trace[i] = frame(pc)
+	}
+	return trace
+}
+
+func openConfig() error {
+	return newTracedError("cannot open config")
+}
+
+func TestStackTrace(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.StackTraceLevelKey, "error")
+	l := newLogger(&b, v)
+	l.SetPrefix("test")
+
+	l.Warn("No trace")
+	expectedLogMessage := "WARN  test: No trace\n"
+	actualLogMessage := b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	n := line() + 1
+	l.Error("Failed")
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) < 3 {
+		t.Fatalf("Expected a stack trace, but got '%s'", b.String())
+	}
+	if lines[0] != "ERROR test: Failed" {
+		t.Errorf("Expected 'ERROR test: Failed', but got '%s'", lines[0])
+	}
+	if expected := "\tgithub.com/johandry/log_test.TestStackTrace"; lines[1] != expected {
+		t.Errorf("Expected '%s', but got '%s'", expected, lines[1])
+	}
+	if expected := fmt.Sprintf("stack_test.go:%d", n); !strings.HasPrefix(lines[2], "\t\t") || !strings.HasSuffix(lines[2], expected) {
+		t.Errorf("Expected '\t\t.../%s', but got '%s'", expected, lines[2])
+	}
+	b.Reset()
+
+	l.SetStackTraceLevel(log.OffLevel)
+	l.Error("Failed")
+	expectedLogMessage = "ERROR test: Failed\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+}
+
+func TestErrorStackTrace(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.StackTraceLevelKey, "error")
+	l := newLogger(&b, v)
+
+	err := fmt.Errorf("starting: %w", errors.Join(errors.New("no trace"), openConfig()))
+	l.WithError(err).Error("Failed")
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) < 4 {
+		t.Fatalf("Expected a stack trace, but got '%s'", b.String())
+	}
+	if expected := "ERROR Failed error=\"starting: no trace\\ncannot open config\""; lines[0] != expected {
+		t.Errorf("Expected '%s', but got '%s'", expected, lines[0])
+	}
+	if expected := "\tcannot open config"; lines[1] != expected {
+		t.Errorf("Expected '%s', but got '%s'", expected, lines[1])
+	}
+	if expected := "\tgithub.com/johandry/log_test.openConfig"; lines[2] != expected {
+		t.Errorf("Expected '%s', but got '%s'", expected, lines[2])
+	}
+	if expected := "\tgithub.com/johandry/log_test.TestErrorStackTrace"; lines[4] != expected {
+		t.Errorf("Expected '%s', but got '%s'", expected, lines[4])
+	}
+	b.Reset()
+
+	l.SetFormatter(&log.JSONFormatter{DisableTimestamp: true})
+	l.WithError(openConfig()).Error("Failed")
+	var data map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &data); err != nil {
+		t.Fatalf("Cannot decode '%s'. %s", b.String(), err)
+	}
+	stack, _ := data[log.StackField].(string)
+	if !strings.HasPrefix(stack, "cannot open config\ngithub.com/johandry/log_test.openConfig\n\t") {
+		t.Errorf("Expected the error stack trace, but got '%s'", stack)
+	}
+	b.Reset()
+}
@@ -0,0 +1,107 @@
+package log
+
+import (
+	stdlog "log"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// stdLogTimestamp matches the date and time added by the standard library
+// logger flags
+var stdLogTimestamp = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} )?(\d{2}:\d{2}:\d{2}(\.\d+)? )?`)
+
+// stdLogLevels are the level words found at the beginning of the messages
+// printed with the standard library logger
+var stdLogLevels = map[string]logrus.Level{
+	"TRACE":   logrus.TraceLevel,
+	"DEBUG":   logrus.DebugLevel,
+	"INFO":    logrus.InfoLevel,
+	"WARN":    logrus.WarnLevel,
+	"WARNING": logrus.WarnLevel,
+	"ERR":     logrus.ErrorLevel,
+	"ERROR":   logrus.ErrorLevel,
+	"FATAL":   logrus.ErrorLevel,
+	"PANIC":   logrus.ErrorLevel,
+}
+
+// stdLogWriter prints every message of a standard library logger as an entry
+// of the logger
+type stdLogWriter struct {
+	logger *Logger
+	prefix string
+	level  logrus.Level
+}
+
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+	msg = stdLogTimestamp.ReplaceAllString(msg, "")
+
+	level := w.level
+	if w.logger.sniffLevels {
+		level, msg = sniffLevel(msg, level)
+	}
+
+	prefix := w.prefix
+	if prefix == "" {
+		prefix = w.logger.GetPrefix()
+	}
+	w.logger.WithField(PrefixField, prefix).Log(level, msg)
+
+	return len(p), nil
+}
+
+// sniffLevel returns the level of a message starting with a level word such as
+// "[ERROR]" or "WARN:" and the message without it. If there is no level word
+// it returns the given level and message.
+func sniffLevel(msg string, level logrus.Level) (logrus.Level, string) {
+	text := strings.TrimLeft(msg, " ")
+	var word, rest string
+	switch {
+	case strings.HasPrefix(text, "["):
+		end := strings.Index(text, "]")
+		if end < 0 {
+			return level, msg
+		}
+		word, rest = text[1:end], text[end+1:]
+	default:
+		end := strings.Index(text, ":")
+		if end < 0 {
+			return level, msg
+		}
+		word, rest = text[:end], text[end+1:]
+	}
+
+	if l, ok := stdLogLevels[strings.ToUpper(word)]; ok {
+		return l, strings.TrimLeft(rest, " ")
+	}
+	return level, msg
+}
+
+// StdLog returns a standard library logger that prints every message as an
+// entry of this logger with the given prefix and level. If the prefix is empty
+// the logger prefix is used.
+//
+// This is used by packages that only accept a *log.Logger, such as the
+// ErrorLog of net/http.Server.
+func (logger *Logger) StdLog(prefix string, level logrus.Level) *stdlog.Logger {
+	return stdlog.New(&stdLogWriter{logger: logger, prefix: prefix, level: level}, "", 0)
+}
+
+// RedirectStdLog sends the output of the standard library global logger to
+// this logger at level info. The returned function restores the previous
+// output, flags and prefix of the global logger.
+func (logger *Logger) RedirectStdLog() (restore func()) {
+	out, flags, prefix := stdlog.Writer(), stdlog.Flags(), stdlog.Prefix()
+
+	stdlog.SetOutput(&stdLogWriter{logger: logger, level: logrus.InfoLevel})
+	stdlog.SetFlags(0)
+	stdlog.SetPrefix("")
+
+	return func() {
+		stdlog.SetOutput(out)
+		stdlog.SetFlags(flags)
+		stdlog.SetPrefix(prefix)
+	}
+}
@@ -0,0 +1,73 @@
+package log_test
+
+import (
+	"bytes"
+	stdlog "log"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func TestStdLog(t *testing.T) {
+	var expectedLogMessage string
+	var actualLogMessage string
+
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.LevelKey, "debug")
+	l := newLogger(&b, v)
+
+	std := l.StdLog("http", logrus.WarnLevel)
+	std.Printf("TLS handshake error from %s", "127.0.0.1")
+	expectedLogMessage = "WARN  http: TLS handshake error from 127.0.0.1\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	std.Print("[ERROR] not sniffed")
+	expectedLogMessage = "WARN  http: [ERROR] not sniffed\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	var expectedLogMessage string
+	var actualLogMessage string
+
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.SniffLevelsKey, true)
+	v.Set(log.LevelKey, "debug")
+	l := newLogger(&b, v)
+	l.SetPrefix("std")
+
+	restore := l.RedirectStdLog()
+	defer restore()
+
+	stdlog.Print("Information")
+	stdlog.Print("[ERROR] Failure")
+	stdlog.Print("debug: Details")
+	stdlog.SetFlags(stdlog.LstdFlags)
+	stdlog.Print("[WARN] With timestamp")
+	expectedLogMessage = "INFO  std: Information\n" +
+		"ERROR std: Failure\n" +
+		"DEBUG std: Details\n" +
+		"WARN  std: With timestamp\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+}
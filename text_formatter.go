@@ -5,58 +5,24 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"runtime"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"golang.org/x/crypto/ssh/terminal"
 
-	"github.com/mgutz/ansi"
 	"github.com/sirupsen/logrus"
 )
 
-// COLORS:
-// ansi.Red
-// ansi.Green
-// ansi.Yellow
-// ansi.Blue
-// ansi.Magenta
-// ansi.Cyan
-// ansi.White
-// ansi.LightBlack
-// ansi.LightRed
-// ansi.LightGreen
-// ansi.LightYellow
-// ansi.LightBlue
-// ansi.LightMagenta
-// ansi.LightCyan
-// ansi.LightWhite
-
-var (
-	colorTimestamp       = ansi.LightBlack
-	colorDebug           = ansi.Blue
-	colorInfo            = ansi.Green
-	colorWarning         = ansi.Yellow
-	colorErrorFatalPanic = ansi.Red
-	colorPrefix          = ansi.LightCyan
-)
-
-var (
-	baseTimestamp time.Time
-)
-
-func init() {
-	baseTimestamp = time.Now()
-}
-
-func miniTS() int {
-	return int(time.Since(baseTimestamp) / time.Second)
-}
+// ansiCodes matches the color codes
+var ansiCodes = regexp.MustCompile("\x1b\\[[0-9;]*m")
 
 // TextFormatter ...
 type TextFormatter struct {
-	// Set to true to bypass checking for a TTY before outputting colors.
+	// Set to true to bypass checking for a TTY and the NO_COLOR environment
+	// variable before outputting colors.
 	ForceColors bool
 
 	// Force disabling colors.
@@ -66,10 +32,17 @@ type TextFormatter struct {
 	// system that already adds timestamps.
 	DisableTimestamp bool
 
-	// Enable logging just the time passed since beginning of execution instead of
-	// the full timestamp when a TTY is attached
+	// Enable logging just the seconds passed since the logger was created
+	// instead of the full timestamp. It's the same as TimestampShort.
 	ShortTimestamp bool
 
+	// TimestampMode is how the timestamp is printed: TimestampFull (default)
+	// with TimestampFormat, TimestampShort, TimestampElapsed with the
+	// milliseconds since the logger was created, TimestampDelta with the time
+	// since the previous entry, or TimestampUTC, TimestampLocal and
+	// TimestampRFC3339Nano for the full timestamp in that zone or format
+	TimestampMode string
+
 	// TimestampFormat to use for display when a full timestamp is printed
 	TimestampFormat string
 
@@ -77,6 +50,72 @@ type TextFormatter struct {
 	// that log extremely frequently and don't use the JSON formatter this may not
 	// be desired.
 	DisableSorting bool
+
+	// Abbreviate the hierarchical prefixes to the first letter of every name
+	// but the last one, like "s/a/handler" for "server/auth/handler"
+	AbbreviatePrefix bool
+
+	// Print the function name of the caller instead of the file and line when
+	// the logger reports the caller
+	CallerFunction bool
+
+	// Multiline is how the messages and values with newlines are printed:
+	// MultilineRaw (default) prints them as they are, MultilineIndent indents
+	// the continuation lines under the message, MultilineHeader repeats the
+	// timestamp, level and prefix in every line and MultilineEscape prints the
+	// newlines as \n to have one line per entry
+	Multiline string
+
+	// Theme has the colors to print the entries, if nil DefaultTheme is used
+	Theme *Theme
+
+	// ColorLevel is the colors supported by the terminal. By default they are
+	// detected from the output and the environment variables NO_COLOR,
+	// CLICOLOR_FORCE, CLICOLOR, TERM and COLORTERM
+	ColorLevel ColorLevel
+
+	// PrefixColors gives a different color to every prefix, if nil the
+	// prefixes have the theme prefix color
+	PrefixColors *PrefixColors
+
+	// PrefixWidth is the width of the prefix column, the shorter prefixes are
+	// padded and the longer ones truncated with an ellipsis. If AutoPrefixWidth
+	// is set the column grows to the longest prefix printed, up to PrefixWidth
+	// if it's not zero.
+	PrefixWidth     int
+	AutoPrefixWidth bool
+
+	// RightAlignLevel prints the level names aligned to the right
+	RightAlignLevel bool
+
+	// FieldsColumn is the column where the fields start, so they are aligned
+	// after the messages. The fields are not aligned if they do not fit in the
+	// terminal width.
+	FieldsColumn int
+
+	// ValueFormat is how the fields with structs, maps and slices are printed:
+	// ValueDefault like fmt.Print, ValueJSON as compact JSON, ValueVerbose with
+	// the struct field names (%+v) or ValueExpand with a parent.child=value
+	// field for every nested value. The times are printed with the timestamp
+	// format and the values implementing fmt.Stringer or encoding.TextMarshaler
+	// as their text.
+	ValueFormat string
+
+	// MaxValueDepth is the nesting level of the values printed in JSON or
+	// expanded, the deeper values are replaced by an ellipsis. If zero, up to
+	// 10 levels are printed.
+	MaxValueDepth int
+
+	// MaxValueLength is the maximum number of characters of a value, the longer
+	// values are truncated with an ellipsis. If zero, the values are not
+	// truncated.
+	MaxValueLength int
+
+	// prefixWidth is the prefix column width when it grows automatically
+	prefixWidth int32
+
+	// clock has the start time of the elapsed and delta timestamps
+	clock *clock
 }
 
 // Format ...
@@ -84,7 +123,7 @@ func (f *TextFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	var b *bytes.Buffer
 	var keys = make([]string, 0, len(entry.Data))
 	for k := range entry.Data {
-		if k == PrefixField {
+		if k == PrefixField || k == StackField {
 			continue
 		}
 		keys = append(keys, k)
@@ -101,23 +140,66 @@ func (f *TextFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 
 	prefixFieldClashes(entry.Data)
 
-	isColorTerminal := checkIfTerminal(entry.Logger.Out) && (runtime.GOOS != "windows")
-	isColored := (f.ForceColors || isColorTerminal) && !f.DisableColors
+	colors := f.colorLevel(entry.Logger.Out)
 
-	timestampFormat := f.TimestampFormat
-	if timestampFormat == "" {
-		timestampFormat = time.StampMilli
+	timestampFormat := f.timestampFormat()
+	multiline := f.Multiline != "" && f.Multiline != MultilineRaw
+	stack, hasStack := entry.Data[StackField]
+
+	start := b.Len()
+	header := f.headerText(entry, colors, timestampFormat)
+	b.WriteString(header)
+	if multiline {
+		b.WriteString(f.messageText(strings.TrimRight(entry.Message, "\r\n"), colors))
+	} else {
+		b.WriteString(f.messageText(entry.Message, colors))
 	}
-	if isColored {
-		f.printColored(b, entry, keys, timestampFormat)
+	if f.FieldsColumn > 0 {
+		var fields bytes.Buffer
+		f.printFields(&fields, entry, keys, colors)
+		b.WriteString(f.fieldsPadding(entry.Logger.Out, b.Bytes()[start:], fields.Bytes()))
+		b.Write(fields.Bytes())
 	} else {
-		f.printNoColor(b, entry, keys, timestampFormat)
+		f.printFields(b, entry, keys, colors)
+	}
+
+	// with escaped newlines the stack trace is printed as a field to keep the
+	// entry in one line
+	if hasStack && f.Multiline == MultilineEscape {
+		f.appendKeyValue(b, StackField, stack)
+	}
+	if multiline {
+		f.printMultiline(b, start, header)
+	}
+	if hasStack && f.Multiline != MultilineEscape {
+		f.printStack(b, fmt.Sprint(stack))
 	}
 
 	b.WriteByte('\n')
 	return b.Bytes(), nil
 }
 
+// timestampFormat returns the format of the full timestamps
+func (f *TextFormatter) timestampFormat() string {
+	if f.TimestampFormat == "" {
+		return time.StampMilli
+	}
+	return f.TimestampFormat
+}
+
+// colorLevel returns the colors supported by the output to w, detected from
+// the environment unless the formatter sets them
+func (f *TextFormatter) colorLevel(w io.Writer) ColorLevel {
+	switch {
+	case f.DisableColors:
+		return ColorNone
+	case f.ColorLevel != ColorAuto:
+		return f.ColorLevel
+	default:
+		return detectColorLevel(w, f.ForceColors)
+	}
+}
+
 func checkIfTerminal(w io.Writer) bool {
 	switch v := w.(type) {
 	case *os.File:
@@ -141,18 +223,12 @@ func prefixFieldClashes(data logrus.Fields) {
 	}
 }
 
-func (f *TextFormatter) levelColor(entry *logrus.Entry) (levelColor string) {
-	switch entry.Level {
-	case logrus.DebugLevel:
-		levelColor = colorDebug
-	case logrus.InfoLevel:
-		levelColor = colorInfo
-	case logrus.WarnLevel:
-		levelColor = colorWarning
-	case logrus.ErrorLevel, logrus.FatalLevel, logrus.PanicLevel:
-		levelColor = colorErrorFatalPanic
+// theme returns the theme of the formatter or the default theme
+func (f *TextFormatter) theme() *Theme {
+	if f.Theme != nil {
+		return f.Theme
 	}
-	return
+	return &DefaultTheme
 }
 
 func (f *TextFormatter) levelText(entry *logrus.Entry) (levelText string) {
@@ -164,57 +240,163 @@ func (f *TextFormatter) levelText(entry *logrus.Entry) (levelText string) {
 	default:
 		levelText = strings.ToUpper(entry.Level.String())
 	}
+	if f.RightAlignLevel {
+		levelText = fmt.Sprintf("%5s", strings.TrimSpace(levelText))
+	}
 	return
 }
 
-func (f *TextFormatter) prefixText(entry *logrus.Entry) (prefixText string) {
-	prefixText = ""
-	if _, ok := entry.Data[PrefixField]; ok {
-		prefixText = fmt.Sprintf(" %s:", entry.Data[PrefixField].(string))
+// prefixText returns the prefix and the spaces to fill the prefix column
+func (f *TextFormatter) prefixText(entry *logrus.Entry) (prefixText, padding string) {
+	prefix, ok := entry.Data[PrefixField].(string)
+	if f.AbbreviatePrefix {
+		prefix = abbreviatePrefix(prefix)
 	}
-	return
+	prefix, padding = fitPrefix(prefix, f.prefixColumnWidth(prefix))
+	if !ok {
+		// keep the column of the entries without prefix
+		if padding != "" {
+			padding += "  "
+		}
+		return "", padding
+	}
+	return fmt.Sprintf(" %s:", prefix), padding
 }
 
-func (f *TextFormatter) timeText(entry *logrus.Entry, timestampFormat string) (timeText string) {
-	timeText = entry.Time.Format(timestampFormat)
-	if f.ShortTimestamp {
-		timeText = fmt.Sprintf("%04d", miniTS())
+func (f *TextFormatter) callerText(entry *logrus.Entry) string {
+	if !entry.HasCaller() {
+		return ""
 	}
-	return
+	if f.CallerFunction {
+		return shortFunction(entry.Caller)
+	}
+	return shortCaller(entry.Caller)
 }
 
-func (f *TextFormatter) printColored(b *bytes.Buffer, entry *logrus.Entry, keys []string, timestampFormat string) {
-	levelColor := f.levelColor(entry)
+// headerText returns the timestamp, level, prefix and caller printed before
+// the message
+func (f *TextFormatter) headerText(entry *logrus.Entry, colors ColorLevel, timestampFormat string) string {
 	levelText := f.levelText(entry)
-	prefixText := f.prefixText(entry)
+	prefixText, padding := f.prefixText(entry)
+	callerText := f.callerText(entry)
 
+	if colors == ColorNone {
+		prefixText += padding
+		if callerText != "" {
+			prefixText = fmt.Sprintf("%s %s", prefixText, callerText)
+		}
+		if f.DisableTimestamp {
+			return fmt.Sprintf("%+5s%s ", levelText, prefixText)
+		}
+		return fmt.Sprintf("[%s] %+5s%s ", f.timeText(entry, timestampFormat), levelText, prefixText)
+	}
+
+	theme := f.theme()
+	if prefixText != "" {
+		prefix, _ := entry.Data[PrefixField].(string)
+		prefixText = " " + paint(colors, f.prefixStyle(prefix, colors), prefixText[1:])
+	}
+	prefixText += padding
+	if callerText != "" {
+		prefixText = fmt.Sprintf("%s %s", prefixText, paint(colors, theme.Caller, callerText))
+	}
+	levelText = paint(colors, theme.level(entry.Level), fmt.Sprintf("%+5s", levelText))
 	if f.DisableTimestamp {
-		fmt.Fprintf(b, "%s%+5s%s%s %s", levelColor, levelText, ansi.Reset, prefixText, entry.Message)
-	} else {
-		timeText := f.timeText(entry, timestampFormat)
-		fmt.Fprintf(b, "%s[%s]%s %s%+5s%s%s %s", colorTimestamp, timeText, ansi.Reset, levelColor, levelText, ansi.Reset, prefixText, entry.Message)
+		return fmt.Sprintf("%s%s ", levelText, prefixText)
 	}
+	timeText := paint(colors, theme.Timestamp, fmt.Sprintf("[%s]", f.timeText(entry, timestampFormat)))
+	return fmt.Sprintf("%s %s%s ", timeText, levelText, prefixText)
+}
 
-	for _, k := range keys {
-		v := entry.Data[k]
-		f.appendKeyValue(b, fmt.Sprintf("%s%s%s", levelColor, k, ansi.Reset), v)
+// promptStyle returns the color of the prefix printed by Ask and AskSecret,
+// which is always colored to highlight the question
+func (f *TextFormatter) promptStyle(prefix string, colors ColorLevel) string {
+	if style := f.prefixStyle(prefix, colors); style != "" {
+		return style
 	}
+	return "cyan+h"
 }
 
-func (f *TextFormatter) printNoColor(b *bytes.Buffer, entry *logrus.Entry, keys []string, timestampFormat string) {
-	levelText := f.levelText(entry)
-	prefixText := f.prefixText(entry)
+// messageText returns the message with the theme color
+func (f *TextFormatter) messageText(message string, colors ColorLevel) string {
+	if colors == ColorNone {
+		return message
+	}
+	return paint(colors, f.theme().Message, message)
+}
 
-	if f.DisableTimestamp {
-		fmt.Fprintf(b, "%+5s%s %s", levelText, prefixText, entry.Message)
-	} else {
-		timeText := f.timeText(entry, timestampFormat)
-		fmt.Fprintf(b, "[%s] %+5s%s %s", timeText, levelText, prefixText, entry.Message)
+func (f *TextFormatter) printFields(b *bytes.Buffer, entry *logrus.Entry, keys []string, colors ColorLevel) {
+	if colors == ColorNone {
+		for _, k := range keys {
+			for _, field := range f.expandField(k, entry.Data[k]) {
+				f.appendKeyValue(b, field.key, field.value)
+			}
+		}
+		return
 	}
 
+	theme := f.theme()
+	keyStyle := theme.Key
+	if keyStyle == "" {
+		keyStyle = theme.level(entry.Level)
+	}
 	for _, k := range keys {
-		v := entry.Data[k]
-		f.appendKeyValue(b, k, v)
+		for _, field := range f.expandField(k, entry.Data[k]) {
+			b.WriteByte(' ')
+			b.WriteString(paint(colors, keyStyle, field.key))
+			b.WriteByte('=')
+			if theme.Value == "" {
+				f.appendValue(b, field.value)
+				continue
+			}
+			var value bytes.Buffer
+			f.appendValue(&value, field.value)
+			b.WriteString(paint(colors, theme.Value, value.String()))
+		}
+	}
+}
+
+// printMultiline rewrites the lines of the entry printed from start according
+// to the Multiline mode
+func (f *TextFormatter) printMultiline(b *bytes.Buffer, start int, header string) {
+	text := strings.TrimRight(string(b.Bytes()[start:]), "\n")
+	if !strings.ContainsAny(text, "\r\n") {
+		return
+	}
+	b.Truncate(start)
+
+	switch f.Multiline {
+	case MultilineEscape:
+		text = strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(text)
+		b.WriteString(text)
+	case MultilineHeader, MultilineIndent:
+		continuation := header
+		if f.Multiline == MultilineIndent {
+			continuation = strings.Repeat(" ", textWidth(header))
+		}
+		for i, line := range strings.Split(text, "\n") {
+			if i > 0 {
+				b.WriteByte('\n')
+				b.WriteString(continuation)
+			}
+			b.WriteString(strings.TrimSuffix(line, "\r"))
+		}
+	default:
+		b.WriteString(text)
+	}
+}
+
+// textWidth returns the number of characters printed in the terminal, without
+// the color codes
+func textWidth(text string) int {
+	return utf8.RuneCountInString(ansiCodes.ReplaceAllString(text, ""))
+}
+
+// printStack prints the stack trace after the entry, indented
+func (f *TextFormatter) printStack(b *bytes.Buffer, stack string) {
+	for _, line := range strings.Split(stack, "\n") {
+		b.WriteString("\n\t")
+		b.WriteString(line)
 	}
 }
 
@@ -238,21 +420,11 @@ func (f *TextFormatter) appendKeyValue(b *bytes.Buffer, key string, value interf
 }
 
 func (f *TextFormatter) appendValue(b *bytes.Buffer, value interface{}) {
-	switch value := value.(type) {
-	case string:
-		if !needsQuoting(value) {
-			b.WriteString(value)
-		} else {
-			fmt.Fprintf(b, "%q", value)
-		}
-	case error:
-		errmsg := value.Error()
-		if !needsQuoting(errmsg) {
-			b.WriteString(errmsg)
-		} else {
-			fmt.Fprintf(b, "%q", errmsg)
-		}
-	default:
-		fmt.Fprint(b, value)
+	text, quote := f.valueText(value)
+	text = f.truncateValue(text)
+	if quote && needsQuoting(text) {
+		fmt.Fprintf(b, "%q", text)
+	} else {
+		b.WriteString(text)
 	}
 }
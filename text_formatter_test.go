@@ -0,0 +1,68 @@
+package log_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/spf13/viper"
+)
+
+func TestMultiline(t *testing.T) {
+	var b bytes.Buffer
+
+	testCases := []struct {
+		name     string
+		mode     string
+		expected string
+	}{
+		{"raw", log.MultilineRaw, "INFO  db: SELECT *\r\nFROM users\n\nWHERE id = 1\n rows=1\n"},
+		{"indent", log.MultilineIndent, "INFO  db: SELECT *\n          FROM users\n          \n          WHERE id = 1 rows=1\n"},
+		{"header", log.MultilineHeader, "INFO  db: SELECT *\nINFO  db: FROM users\nINFO  db: \nINFO  db: WHERE id = 1 rows=1\n"},
+		{"escape", log.MultilineEscape, "INFO  db: SELECT *\\r\\nFROM users\\n\\nWHERE id = 1 rows=1\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := viper.New()
+			v.Set(log.DisableColorsKey, true)
+			v.Set(log.DisableTimestampKey, true)
+			v.Set(log.MultilineKey, tc.mode)
+			l := newLogger(&b, v)
+			l.SetPrefix("db")
+
+			l.Prefix("db").WithField("rows", 1).Info("SELECT *\r\nFROM users\n\nWHERE id = 1\n")
+			actualLogMessage := b.String()
+			if actualLogMessage != tc.expected {
+				t.Errorf("Expected %q, but got %q", tc.expected, actualLogMessage)
+			}
+			b.Reset()
+
+			l.Info("Single line")
+			expectedLogMessage := "INFO  db: Single line\n"
+			actualLogMessage = b.String()
+			if actualLogMessage != expectedLogMessage {
+				t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+			}
+			b.Reset()
+		})
+	}
+}
+
+func TestMultilineColored(t *testing.T) {
+	var b bytes.Buffer
+
+	v := viper.New()
+	v.Set(log.ForceColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.MultilineKey, log.MultilineIndent)
+	l := newLogger(&b, v)
+	l.SetPrefix("db")
+
+	l.Info("SELECT *\nFROM users")
+	expectedLogMessage := "\x1b[0;32mINFO \x1b[0m db: SELECT *\n          FROM users\n"
+	actualLogMessage := b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+}
@@ -0,0 +1,220 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mgutz/ansi"
+	"github.com/sirupsen/logrus"
+)
+
+// Theme has the colors used by the TextFormatter, every color is a
+// github.com/mgutz/ansi style such as "red", "red+b" or "red+b:white", where
+// the colors could also be 24-bit colors like "#ff8700". An empty style prints
+// the text without color, except for the field keys which take the color of
+// the level. The colors not supported by the terminal are replaced by the
+// nearest supported color.
+type Theme struct {
+	Timestamp string
+	Trace     string
+	Debug     string
+	Info      string
+	Warning   string
+	Error     string
+	Fatal     string
+	Panic     string
+	Prefix    string
+	Caller    string
+	Key       string
+	Value     string
+	Message   string
+}
+
+// DefaultTheme is the theme used when the formatter does not have one
+var DefaultTheme = Theme{
+	Timestamp: "black+h",
+	Debug:     "blue",
+	Info:      "green",
+	Warning:   "yellow",
+	Error:     "red",
+	Fatal:     "red",
+	Panic:     "red",
+	Caller:    "white+d",
+}
+
+// LightTheme has dark colors for terminals with a light background
+var LightTheme = Theme{
+	Timestamp: "black+h",
+	Trace:     "black+h",
+	Debug:     "blue",
+	Info:      "green",
+	Warning:   "magenta",
+	Error:     "red",
+	Fatal:     "red+b",
+	Panic:     "red+b",
+	Prefix:    "blue+b",
+	Caller:    "black+h",
+	Message:   "black",
+}
+
+// DarkTheme has bright colors for terminals with a dark background
+var DarkTheme = Theme{
+	Timestamp: "black+h",
+	Trace:     "white+d",
+	Debug:     "blue+h",
+	Info:      "green+h",
+	Warning:   "yellow+h",
+	Error:     "red+h",
+	Fatal:     "red+b",
+	Panic:     "white+b:red",
+	Prefix:    "cyan+h",
+	Caller:    "white+d",
+	Message:   "white+h",
+}
+
+// SolarizedTheme uses the Solarized palette (https://ethanschoonover.com/solarized)
+// from the 256 colors
+var SolarizedTheme = Theme{
+	Timestamp: "240",
+	Trace:     "240",
+	Debug:     "33",
+	Info:      "64",
+	Warning:   "136",
+	Error:     "160",
+	Fatal:     "166",
+	Panic:     "125",
+	Prefix:    "37",
+	Caller:    "240",
+	Key:       "61",
+	Value:     "244",
+}
+
+// themes are the built-in themes by name
+var themes = map[string]*Theme{
+	"default":   &DefaultTheme,
+	"light":     &LightTheme,
+	"dark":      &DarkTheme,
+	"solarized": &SolarizedTheme,
+}
+
+// NewTheme returns a copy of the built-in theme with the given name, or the
+// default theme if the name is empty, with the colors replaced by the given
+// ones. The keys of the colors are the fields of the theme in lowercase, such
+// as "timestamp", "info" or "key".
+func NewTheme(name string, colors map[string]string) (*Theme, error) {
+	if name == "" {
+		name = "default"
+	}
+	base, ok := themes[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme %q, it should be one of %s", name, strings.Join(themeNames(), ", "))
+	}
+
+	theme := *base
+	for key, style := range colors {
+		if err := theme.set(key, style); err != nil {
+			return nil, err
+		}
+	}
+	return &theme, nil
+}
+
+func themeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, fmt.Sprintf("%q", name))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// set sets the style of the theme field with the given name
+func (t *Theme) set(key, style string) error {
+	if err := validateStyle(style); err != nil {
+		return fmt.Errorf("invalid color for %q. %s", key, err)
+	}
+
+	fields := map[string]*string{
+		"timestamp": &t.Timestamp,
+		"trace":     &t.Trace,
+		"debug":     &t.Debug,
+		"info":      &t.Info,
+		"warning":   &t.Warning,
+		"error":     &t.Error,
+		"fatal":     &t.Fatal,
+		"panic":     &t.Panic,
+		"prefix":    &t.Prefix,
+		"caller":    &t.Caller,
+		"key":       &t.Key,
+		"value":     &t.Value,
+		"message":   &t.Message,
+	}
+	field, ok := fields[strings.ToLower(key)]
+	if !ok {
+		return fmt.Errorf("unknown theme color %q", key)
+	}
+	*field = style
+	return nil
+}
+
+// level returns the style of the level
+func (t *Theme) level(level logrus.Level) string {
+	switch level {
+	case logrus.TraceLevel:
+		return t.Trace
+	case logrus.DebugLevel:
+		return t.Debug
+	case logrus.InfoLevel:
+		return t.Info
+	case logrus.WarnLevel:
+		return t.Warning
+	case logrus.ErrorLevel:
+		return t.Error
+	case logrus.FatalLevel:
+		return t.Fatal
+	case logrus.PanicLevel:
+		return t.Panic
+	default:
+		return ""
+	}
+}
+
+// validateStyle returns an error if the style is not a valid
+// github.com/mgutz/ansi style, "foreground+attributes:background+attributes"
+func validateStyle(style string) error {
+	if style == "" || style == "reset" || style == "off" {
+		return nil
+	}
+	parts := strings.Split(style, ":")
+	if len(parts) > 2 {
+		return fmt.Errorf("too many colors in %q", style)
+	}
+	for _, part := range parts {
+		color, attrs := part, ""
+		if i := strings.Index(part, "+"); i >= 0 {
+			color, attrs = part[:i], part[i+1:]
+		}
+		if strings.HasPrefix(color, "#") {
+			if _, _, _, err := parseHexColor(color); err != nil {
+				return err
+			}
+		} else if _, ok := ansi.Colors[color]; !ok && color != "" {
+			return fmt.Errorf("unknown color %q in %q", color, style)
+		}
+		if i := strings.IndexFunc(attrs, func(r rune) bool { return !strings.ContainsRune("bdBuish", r) }); i >= 0 {
+			return fmt.Errorf("unknown attribute %q in %q", attrs[i], style)
+		}
+	}
+	return nil
+}
+
+// paint returns the text with the color of the style, degraded to the colors
+// supported
+func paint(colors ColorLevel, style, text string) string {
+	code := colorCode(colors, style)
+	if code == "" {
+		return text
+	}
+	return code + text + ansi.Reset
+}
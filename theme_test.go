@@ -0,0 +1,97 @@
+package log_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/viper"
+)
+
+func TestNewTheme(t *testing.T) {
+	theme, err := log.NewTheme("solarized", map[string]string{"Info": "green+b", "message": "white:blue"})
+	if err != nil {
+		t.Fatalf("Unexpected error creating the theme. %s", err)
+	}
+	if theme.Info != "green+b" || theme.Message != "white:blue" || theme.Debug != log.SolarizedTheme.Debug {
+		t.Errorf("Expected the solarized theme with new info and message colors, but got %+v", theme)
+	}
+	if log.SolarizedTheme.Info == "green+b" {
+		t.Errorf("Expected the built-in theme to not be modified")
+	}
+
+	theme, err = log.NewTheme("", nil)
+	if err != nil || *theme != log.DefaultTheme {
+		t.Errorf("Expected the default theme, but got %+v, %v", theme, err)
+	}
+
+	testCases := []struct {
+		name   string
+		colors map[string]string
+	}{
+		{"neon", nil},
+		{"dark", map[string]string{"border": "red"}},
+		{"dark", map[string]string{"info": "pink"}},
+		{"dark", map[string]string{"info": "red+x"}},
+		{"dark", map[string]string{"info": "red:blue:green"}},
+	}
+	for _, tc := range testCases {
+		if _, err := log.NewTheme(tc.name, tc.colors); err == nil {
+			t.Errorf("Expected an error for theme %q with colors %v", tc.name, tc.colors)
+		}
+	}
+}
+
+func TestTheme(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.ForceColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.ThemeKey, "dark")
+	v.Set(log.ColorsKey, map[string]interface{}{"key": "magenta", "value": "white"})
+	l := newLogger(&b, v)
+	l.SetPrefix("db")
+
+	l.Prefix("db").WithField("rows", 1).Warn("Slow query")
+	expectedLogMessage := ansi.ColorCode("yellow+h") + "WARN " + ansi.Reset + " " +
+		ansi.ColorCode("cyan+h") + "db:" + ansi.Reset + " " +
+		ansi.ColorCode("white+h") + "Slow query" + ansi.Reset + " " +
+		ansi.ColorCode("magenta") + "rows" + ansi.Reset + "=" +
+		ansi.ColorCode("white") + "1" + ansi.Reset + "\n"
+	actualLogMessage := b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	// every logger has its own theme
+	c := l.Copy()
+	c.Formatter.(*log.TextFormatter).Theme.Warning = "red"
+	l.Warn("Slow query")
+	expectedLogMessage = ansi.ColorCode("yellow+h") + "WARN " + ansi.Reset
+	actualLogMessage = b.String()
+	if !strings.HasPrefix(actualLogMessage, expectedLogMessage) {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+}
+
+func TestValidateTheme(t *testing.T) {
+	v := viper.New()
+	v.Set(log.ThemeKey, "neon")
+	v.Set(log.ColorsKey, map[string]string{"info": "pink"})
+
+	err := log.Validate(v)
+	var errs log.ConfigErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected ConfigErrors, but got %v", err)
+	}
+	keys := errs.Keys()
+	if len(keys) != 2 || keys[0] != log.ColorsKey || keys[1] != log.ThemeKey {
+		t.Errorf("Expected errors for %s and %s, but got %v", log.ColorsKey, log.ThemeKey, keys)
+	}
+}
@@ -0,0 +1,162 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultClock is used by the text formatters not created for a logger, it
+// starts when the program starts
+var defaultClock = newClock()
+
+// clock has the time the logger was created, or reset, and the time of the
+// last entry to print the relative timestamps
+type clock struct {
+	mu   sync.Mutex
+	base time.Time
+	last time.Time
+}
+
+func newClock() *clock {
+	c := &clock{}
+	c.reset(time.Now())
+	return c
+}
+
+// reset starts counting the time again from now
+func (c *clock) reset(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.base = now
+	c.last = now
+}
+
+// copy returns a clock with the same times, or nil if there is no clock
+func (c *clock) copy() *clock {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &clock{base: c.base, last: c.last}
+}
+
+// elapsed returns the time passed from the base time to t
+func (c *clock) elapsed(t time.Time) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.Before(c.base) {
+		return 0
+	}
+	return t.Sub(c.base)
+}
+
+// delta returns the time passed from the last entry to t, which becomes the
+// last entry. Concurrent entries may arrive out of order, they get a zero
+// delta.
+func (c *clock) delta(t time.Time) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.Before(c.last) {
+		return 0
+	}
+	d := t.Sub(c.last)
+	c.last = t
+	return d
+}
+
+// ResetTimestamp starts counting the elapsed and delta timestamps of the
+// logger from now
+func (logger *Logger) ResetTimestamp() {
+	if f, ok := logger.formatter().(*TextFormatter); ok && f.clock != nil {
+		f.clock.reset(time.Now())
+	}
+}
+
+// withClock gives a text formatter without clock the clock of the current
+// formatter of the logger, or a new one, so the relative timestamps continue
+// when the formatter is replaced
+func withClock(formatter, current logrus.Formatter) {
+	f, ok := formatter.(*TextFormatter)
+	if !ok || f.clock != nil {
+		return
+	}
+	if c, ok := current.(*TextFormatter); ok && c.clock != nil {
+		f.clock = c.clock
+		return
+	}
+	f.clock = newClock()
+}
+
+// timeClock returns the clock of the formatter, or the clock started with the
+// program if the formatter was not created for a logger
+func (f *TextFormatter) timeClock() *clock {
+	if f.clock != nil {
+		return f.clock
+	}
+	return defaultClock
+}
+
+// timestampMode returns the timestamp mode, ShortTimestamp is an alias of
+// TimestampShort
+func (f *TextFormatter) timestampMode() string {
+	if f.TimestampMode == "" && f.ShortTimestamp {
+		return TimestampShort
+	}
+	return f.TimestampMode
+}
+
+func (f *TextFormatter) timeText(entry *logrus.Entry, timestampFormat string) string {
+	c := f.timeClock()
+	switch f.timestampMode() {
+	case TimestampShort:
+		return fmt.Sprintf("%04d", int(c.elapsed(entry.Time)/time.Second))
+	case TimestampElapsed:
+		elapsed := c.elapsed(entry.Time)
+		return fmt.Sprintf("%04d.%03d", int(elapsed/time.Second), int(elapsed%time.Second/time.Millisecond))
+	case TimestampDelta:
+		return "+" + deltaText(c.delta(entry.Time))
+	default:
+		return f.formatTime(entry.Time, timestampFormat)
+	}
+}
+
+// formatTime returns the full timestamp of t in the zone or format of the
+// timestamp mode
+func (f *TextFormatter) formatTime(t time.Time, timestampFormat string) string {
+	switch f.timestampMode() {
+	case TimestampUTC:
+		return t.UTC().Format(timestampFormat)
+	case TimestampLocal:
+		return t.Local().Format(timestampFormat)
+	case TimestampRFC3339Nano:
+		return t.Format(time.RFC3339Nano)
+	default:
+		return t.Format(timestampFormat)
+	}
+}
+
+// deltaText returns the duration with milliseconds precision, or microseconds
+// if it's shorter than a millisecond
+func deltaText(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "0ms"
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	default:
+		return d.Round(time.Millisecond).String()
+	}
+}
+
+func validateTimestampMode(mode string) error {
+	switch mode {
+	case "", TimestampFull, TimestampShort, TimestampElapsed, TimestampDelta, TimestampUTC, TimestampLocal, TimestampRFC3339Nano:
+		return nil
+	default:
+		return fmt.Errorf("unknown timestamp mode %q, it should be %q, %q, %q, %q, %q, %q or %q", mode, TimestampFull, TimestampShort, TimestampElapsed, TimestampDelta, TimestampUTC, TimestampLocal, TimestampRFC3339Nano)
+	}
+}
@@ -0,0 +1,121 @@
+package log_test
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/johandry/log"
+	"github.com/spf13/viper"
+)
+
+func TestElapsedTimestamp(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.TimestampModeKey, log.TimestampElapsed)
+	l := newLogger(&b, v)
+
+	l.ResetTimestamp()
+	l.WithTime(time.Now().Add(90 * time.Second)).Info("Later")
+	expectedLogMessage := regexp.MustCompile(`^\[0090\.\d{3}\] INFO  Later\n$`)
+	actualLogMessage := b.String()
+	if !expectedLogMessage.MatchString(actualLogMessage) {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	l.WithTime(time.Now().Add(-time.Hour)).Info("Before")
+	expectedLogMessage = regexp.MustCompile(`^\[0000\.000\] INFO  Before\n$`)
+	actualLogMessage = b.String()
+	if !expectedLogMessage.MatchString(actualLogMessage) {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+}
+
+func TestElapsedTimestampSetFormatter(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.TimestampModeKey, log.TimestampElapsed)
+	l := newLogger(&b, v)
+
+	// the new formatter keeps counting from the logger clock
+	l.ResetTimestamp()
+	l.SetFormatter(&log.TextFormatter{DisableColors: true, TimestampMode: log.TimestampElapsed})
+	l.WithTime(time.Now().Add(90 * time.Second)).Info("Later")
+	expectedLogMessage := regexp.MustCompile(`^\[0090\.\d{3}\] INFO  Later\n$`)
+	actualLogMessage := b.String()
+	if !expectedLogMessage.MatchString(actualLogMessage) {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+}
+
+func TestDeltaTimestamp(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.TimestampModeKey, log.TimestampDelta)
+	l := newLogger(&b, v)
+
+	start := time.Now().Add(time.Hour)
+	l.WithTime(start).Info("Start")
+	b.Reset()
+
+	// the copy has its own previous entry
+	c := l.Copy()
+	c.WithTime(start.Add(time.Minute)).Info("Copy")
+	b.Reset()
+
+	l.WithTime(start.Add(12 * time.Millisecond)).Info("One")
+	l.WithTime(start.Add(1512 * time.Millisecond)).Info("Two")
+	l.WithTime(start.Add(1512*time.Millisecond + 250*time.Microsecond)).Info("Three")
+	l.WithTime(start).Info("Late")
+	expectedLogMessage := "[+12ms] INFO  One\n" +
+		"[+1.5s] INFO  Two\n" +
+		"[+250µs] INFO  Three\n" +
+		"[+0ms] INFO  Late\n"
+	actualLogMessage := b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+}
+
+func TestTimestampPresets(t *testing.T) {
+	ts := time.Date(2020, 5, 17, 20, 30, 15, 123456789, time.FixedZone("CEST", 2*60*60))
+
+	testCases := []struct {
+		mode     string
+		expected string
+	}{
+		{log.TimestampFull, "[20:30:15] INFO  Hello\n"},
+		{log.TimestampUTC, "[18:30:15] INFO  Hello\n"},
+		{log.TimestampRFC3339Nano, "[2020-05-17T20:30:15.123456789+02:00] INFO  Hello\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.mode, func(t *testing.T) {
+			var b bytes.Buffer
+			v := viper.New()
+			v.Set(log.DisableColorsKey, true)
+			v.Set(log.TimestampFormatKey, "15:04:05")
+			v.Set(log.TimestampModeKey, tc.mode)
+			l := newLogger(&b, v)
+
+			l.WithTime(ts).Info("Hello")
+			if actual := b.String(); actual != tc.expected {
+				t.Errorf("Expected %q, but got %q", tc.expected, actual)
+			}
+		})
+	}
+
+	v := viper.New()
+	v.Set(log.TimestampModeKey, "monotonic")
+	if err := log.Validate(v); err == nil {
+		t.Errorf("Expected an error for an invalid %s", log.TimestampModeKey)
+	}
+}
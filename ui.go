@@ -1,19 +1,113 @@
 package log
 
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
 // Logger also implements the interface cli.Ui from github.com/mitchellh/cli to
 // print logs using the text formatter
 
-// Ask asks the user for input using the given query. This UI do not ask
-// questions so it implements nothing.
+// ErrNotTerminal is returned by AskSecret when the input is not a terminal, so
+// the keystrokes cannot be hidden
+var ErrNotTerminal = errors.New("cannot ask for a secret, the input is not a terminal")
+
+// SetInput sets the reader used by Ask and AskSecret to read the user input.
+// By default it's the standard input.
+func (logger *Logger) SetInput(input io.Reader) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.input = input
+	logger.inputReader = nil
+}
+
+// reader returns the buffered reader of the user input
+func (logger *Logger) reader() (io.Reader, *bufio.Reader) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if logger.input == nil {
+		logger.input = os.Stdin
+	}
+	if logger.inputReader == nil {
+		logger.inputReader = bufio.NewReader(logger.input)
+	}
+	return logger.input, logger.inputReader
+}
+
+// promptOutput returns where the questions are printed: the UI output if
+// set, otherwise the standard error. They are never printed to the log output,
+// which could be a file or a JSON stream the user does not see, nor to the
+// standard output used by default for the raw messages, which could be piped
+// to another program.
+func (logger *Logger) promptOutput() io.Writer {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if logger.uiOutput != nil && !logger.stdoutUI {
+		return logger.uiOutput
+	}
+	return os.Stderr
+}
+
+// prompt prints the query with the prefix, using the colors of the text
+// formatter. The prompt is printed with a single write so it's not mixed with
+// the entries printed concurrently to the same terminal.
+func (logger *Logger) prompt(query string) {
+	out := logger.promptOutput()
+	prefix := logger.GetPrefix()
+	colors := ColorNone
+	formatter, ok := logger.formatter().(*TextFormatter)
+	if ok {
+		colors = formatter.colorLevel(out)
+	}
+
+	var text string
+	switch {
+	case prefix == "":
+		text = fmt.Sprintf("%s ", query)
+	case colors != ColorNone:
+		text = fmt.Sprintf("%s %s ", paint(colors, formatter.promptStyle(prefix, colors), prefix+":"), query)
+	default:
+		text = fmt.Sprintf("%s: %s ", prefix, query)
+	}
+	io.WriteString(out, text)
+}
+
+// Ask asks the user for input using the given query. The answer is read from
+// the input reader until the end of the line.
 func (logger *Logger) Ask(query string) (string, error) {
-	return "", nil
+	_, r := logger.reader()
+	logger.prompt(query)
+
+	line, err := r.ReadString('\n')
+	if err != nil && !(err == io.EOF && line != "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
 }
 
 // AskSecret asks the user for input using the given query, but does not echo
-// the keystrokes to the terminal. This UI do not ask questions so it implements
-// nothing.
+// the keystrokes to the terminal. It returns ErrNotTerminal if the input is not
+// a terminal.
 func (logger *Logger) AskSecret(query string) (string, error) {
-	return "", nil
+	input, _ := logger.reader()
+	file, ok := input.(*os.File)
+	if !ok || !terminal.IsTerminal(int(file.Fd())) {
+		return "", ErrNotTerminal
+	}
+
+	logger.prompt(query)
+	secret, err := terminal.ReadPassword(int(file.Fd()))
+	fmt.Fprintln(logger.promptOutput())
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
 }
 
 // Error is used for any error messages that might appear on standard error.
@@ -26,9 +120,28 @@ func (logger *Logger) Info(message string) {
 	logger.info(message)
 }
 
-// Output is called for normal standard output.
+// SetUIOutput sets the writer where Output prints the raw messages, so the
+// program output is not mixed with the log entries. If it's nil, Output prints
+// the messages as info entries.
+func (logger *Logger) SetUIOutput(out io.Writer) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.uiOutput = out
+	logger.stdoutUI = false
+}
+
+// Output is called for normal standard output. If there is an UI output the
+// message is printed as is, otherwise it's printed as an info entry.
 func (logger *Logger) Output(message string) {
-	logger.Print(message)
+	logger.mu.Lock()
+	out := logger.uiOutput
+	logger.mu.Unlock()
+
+	if out == nil {
+		logger.Print(message)
+		return
+	}
+	fmt.Fprintln(out, message)
 }
 
 // Warn is used for any warning messages that might appear on standard error.
@@ -2,6 +2,10 @@ package log_test
 
 import (
 	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/johandry/log"
@@ -35,29 +39,45 @@ func TestUI(t *testing.T) {
 	b.Reset()
 
 	// Ask(string) (string, error)
-	resp, err := ui.Ask("Something")
+	l.SetInput(strings.NewReader("Answer\r\nLast"))
+	resp, err := ui.Ask("Something?")
 	if err != nil {
 		t.Errorf("Error trying to ask something. %v", err)
 	}
-	expectedLogMessage = ""
+	expectedLogMessage = "Answer"
 	actualLogMessage = resp
 	if actualLogMessage != expectedLogMessage {
 		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
 	}
+	// the prompt is printed to the standard error, not to the log output
+	if b.Len() != 0 {
+		t.Errorf("Expected no prompt in the log output, but got '%s'", b.String())
+	}
 	b.Reset()
 
-	// AskSecret(string) (string, error)
-	resp, err = ui.AskSecret("Something")
+	resp, err = ui.Ask("Something else?")
 	if err != nil {
-		t.Errorf("Error trying to ask a secret. %v", err)
+		t.Errorf("Error trying to ask something. %v", err)
 	}
-	expectedLogMessage = ""
+	expectedLogMessage = "Last"
 	actualLogMessage = resp
 	if actualLogMessage != expectedLogMessage {
 		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
 	}
 	b.Reset()
 
+	if _, err = ui.Ask("Nothing else?"); err != io.EOF {
+		t.Errorf("Expected EOF asking with no input, but got %v", err)
+	}
+	b.Reset()
+
+	// AskSecret(string) (string, error)
+	_, err = ui.AskSecret("Something")
+	if err != log.ErrNotTerminal {
+		t.Errorf("Expected an error asking a secret without a terminal, but got %v", err)
+	}
+	b.Reset()
+
 	// Output(string)
 	ui.Output("Output")
 	expectedLogMessage = " INFO  Test: Output"
@@ -94,3 +114,94 @@ func TestUI(t *testing.T) {
 	}
 	b.Reset()
 }
+
+// TestUIOutput test the Output method printing raw messages to a different
+// writer than the log entries
+func TestUIOutput(t *testing.T) {
+	var expectedLogMessage string
+	var actualLogMessage string
+
+	var b, out bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.RawOutputKey, true)
+	v.Set(log.UIOutputKey, &out)
+	l := newLogger(&b, v)
+	l.SetPrefix("test")
+
+	var ui cli.Ui = l
+
+	ui.Output(`{"key": "value"}`)
+	ui.Warn("Warning")
+
+	expectedLogMessage = "{\"key\": \"value\"}\n"
+	actualLogMessage = out.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	expectedLogMessage = "WARN  test: Warning\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+	out.Reset()
+
+	l.SetInput(strings.NewReader("Answer\n"))
+	if _, err := ui.Ask("Something?"); err != nil {
+		t.Errorf("Error trying to ask something. %v", err)
+	}
+	expectedLogMessage = "test: Something? "
+	actualLogMessage = out.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected prompt '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	if b.Len() != 0 {
+		t.Errorf("Expected no prompt in the log output, but got '%s'", b.String())
+	}
+}
+
+func TestUIRawOutputPrompt(t *testing.T) {
+	dir := t.TempDir()
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatalf("Cannot create standard output file. %s", err)
+	}
+	defer stdout.Close()
+	stderr, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatalf("Cannot create standard error file. %s", err)
+	}
+	defer stderr.Close()
+	defer func(stdout, stderr *os.File) { os.Stdout, os.Stderr = stdout, stderr }(os.Stdout, os.Stderr)
+	os.Stdout, os.Stderr = stdout, stderr
+
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.RawOutputKey, true)
+	l := newLogger(&b, v)
+	l.SetPrefix("test")
+	l.SetInput(strings.NewReader("Answer\n"))
+
+	// the raw messages could be piped to another program, the prompts are not
+	// printed with them
+	l.Output(`{"key": "value"}`)
+	if _, err := l.Ask("Something?"); err != nil {
+		t.Errorf("Error trying to ask something. %v", err)
+	}
+
+	expectedLogMessage := "{\"key\": \"value\"}\n"
+	actualLogMessage, _ := os.ReadFile(stdout.Name())
+	if string(actualLogMessage) != expectedLogMessage {
+		t.Errorf("Expected '%s' in the standard output, but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	expectedLogMessage = "test: Something? "
+	actualLogMessage, _ = os.ReadFile(stderr.Name())
+	if string(actualLogMessage) != expectedLogMessage {
+		t.Errorf("Expected prompt '%s' in the standard error, but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+}
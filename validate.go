@@ -0,0 +1,300 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// boolKeys are the viper variables that should have a boolean value
+var boolKeys = []string{
+	ForceColorsKey,
+	DisableColorsKey,
+	DisableTimestampKey,
+	ShortTimestampKey,
+	DailyKey,
+	CompressKey,
+	ReopenKey,
+	SniffLevelsKey,
+	RawOutputKey,
+	AbbreviatePrefixKey,
+	CallerKey,
+	HashPrefixColorsKey,
+	AutoPrefixWidthKey,
+	RightAlignLevelKey,
+}
+
+// sizeKeys are the viper variables that should have a non-negative integer
+// value
+var sizeKeys = []string{
+	MaxSizeKey,
+	MaxAgeKey,
+	MaxBackupsKey,
+	PrefixWidthKey,
+	FieldsColumnKey,
+	MaxValueDepthKey,
+	MaxValueLengthKey,
+}
+
+// ConfigError is the error of an invalid viper variable
+type ConfigError struct {
+	Key   string
+	Value interface{}
+	Err   error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid value %v for %s. %s", e.Value, e.Key, e.Err)
+}
+
+// Unwrap returns the reason of the error
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigErrors collects the errors of every invalid viper variable
+type ConfigErrors []*ConfigError
+
+func (e ConfigErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return "invalid log configuration: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the error of every invalid viper variable
+func (e ConfigErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Keys returns the invalid viper variables
+func (e ConfigErrors) Keys() []string {
+	keys := make([]string, 0, len(e))
+	for _, err := range e {
+		keys = append(keys, err.Key)
+	}
+	return keys
+}
+
+// Validate verifies the value of every log viper variable set in v. It returns
+// nil if all of them are valid or a ConfigErrors with every invalid variable.
+func Validate(v *viper.Viper) error {
+	var errs ConfigErrors
+	add := func(key string, err error) {
+		if err != nil {
+			errs = append(errs, &ConfigError{Key: key, Value: v.Get(key), Err: err})
+		}
+	}
+
+	if v.IsSet(LevelKey) {
+		_, err := ParsePrefixLevel(v.GetString(LevelKey))
+		add(LevelKey, err)
+	}
+	if v.IsSet(OutputKey) {
+		add(OutputKey, validateOutput(v.Get(OutputKey)))
+	}
+	if v.IsSet(UIOutputKey) {
+		add(UIOutputKey, validateOutput(v.Get(UIOutputKey)))
+	}
+	if v.IsSet(LevelsKey) {
+		add(LevelsKey, validateLevels(v.Get(LevelsKey)))
+	}
+	if v.IsSet(InputKey) {
+		add(InputKey, validateInput(v.Get(InputKey)))
+	}
+	if v.IsSet(StackTraceLevelKey) {
+		_, err := ParsePrefixLevel(v.GetString(StackTraceLevelKey))
+		add(StackTraceLevelKey, err)
+	}
+	if v.IsSet(FormatKey) {
+		add(FormatKey, validateFormat(v.GetString(FormatKey)))
+	}
+	if v.IsSet(MultilineKey) {
+		add(MultilineKey, validateMultiline(v.GetString(MultilineKey)))
+	}
+	if v.IsSet(TimestampModeKey) {
+		add(TimestampModeKey, validateTimestampMode(v.GetString(TimestampModeKey)))
+	}
+	if v.IsSet(ValueFormatKey) {
+		add(ValueFormatKey, validateValueFormat(v.GetString(ValueFormatKey)))
+	}
+	if v.IsSet(ThemeKey) {
+		add(ThemeKey, validateTheme(v.GetString(ThemeKey), nil))
+	}
+	if v.IsSet(ColorsKey) {
+		add(ColorsKey, validateTheme("", v.Get(ColorsKey)))
+	}
+	if v.IsSet(ColorLevelKey) {
+		_, err := ParseColorLevel(v.GetString(ColorLevelKey))
+		add(ColorLevelKey, err)
+	}
+	if v.IsSet(PrefixPaletteKey) {
+		add(PrefixPaletteKey, validatePrefixPalette(v.Get(PrefixPaletteKey)))
+	}
+	if v.IsSet(PrefixColorsKey) {
+		add(PrefixColorsKey, validatePrefixColors(v.Get(PrefixColorsKey)))
+	}
+	if v.IsSet(FilenameKey) && !v.IsSet(OutputKey) {
+		add(FilenameKey, validateFilename(v.GetString(FilenameKey)))
+	}
+	if v.IsSet(TimestampFormatKey) {
+		_, err := cast.ToStringE(v.Get(TimestampFormatKey))
+		add(TimestampFormatKey, err)
+	}
+	for _, key := range boolKeys {
+		if v.IsSet(key) {
+			_, err := cast.ToBoolE(v.Get(key))
+			add(key, err)
+		}
+	}
+	for _, key := range sizeKeys {
+		if v.IsSet(key) {
+			add(key, validateSize(v.Get(key)))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Key < errs[j].Key })
+	return errs
+}
+
+// NewWithError creates a new Logger configured from an existing viper
+// instance, like New, but it returns an error if any variable is invalid or
+// the log file cannot be opened.
+func NewWithError(v *viper.Viper) (*Logger, error) {
+	if err := Validate(v); err != nil {
+		return nil, err
+	}
+
+	// The log file is shared, so opening it here makes New use the same file
+	if !v.IsSet(OutputKey) && v.IsSet(FilenameKey) {
+		if _, err := ConfigFromViper(v).rotatingFile(); err != nil {
+			return nil, ConfigErrors{{Key: FilenameKey, Value: v.Get(FilenameKey), Err: err}}
+		}
+	}
+
+	return New(v), nil
+}
+
+func validateOutput(value interface{}) error {
+	if _, ok := value.(io.Writer); !ok {
+		return fmt.Errorf("%T is not an io.Writer", value)
+	}
+	return nil
+}
+
+func validateLevels(value interface{}) error {
+	levels, err := cast.ToStringMapStringE(value)
+	if err != nil {
+		return err
+	}
+	for prefix, name := range levels {
+		if _, err := path.Match(prefix, ""); err != nil {
+			return fmt.Errorf("invalid prefix pattern %q. %s", prefix, err)
+		}
+		if _, err := ParsePrefixLevel(name); err != nil {
+			return fmt.Errorf("invalid level for prefix %q. %s", prefix, err)
+		}
+	}
+	return nil
+}
+
+func validateInput(value interface{}) error {
+	if _, ok := value.(io.Reader); !ok {
+		return fmt.Errorf("%T is not an io.Reader", value)
+	}
+	return nil
+}
+
+func validateFormat(format string) error {
+	switch format {
+	case "", TextFormat, JSONFormat, LogfmtFormat:
+		return nil
+	default:
+		return fmt.Errorf("unknown log format %q, it should be %q, %q or %q", format, TextFormat, JSONFormat, LogfmtFormat)
+	}
+}
+
+func validateMultiline(mode string) error {
+	switch mode {
+	case "", MultilineRaw, MultilineIndent, MultilineHeader, MultilineEscape:
+		return nil
+	default:
+		return fmt.Errorf("unknown multiline mode %q, it should be %q, %q, %q or %q", mode, MultilineRaw, MultilineIndent, MultilineHeader, MultilineEscape)
+	}
+}
+
+func validateTheme(name string, colors interface{}) error {
+	styles, err := cast.ToStringMapStringE(colors)
+	if err != nil && colors != nil {
+		return err
+	}
+	_, err = NewTheme(name, styles)
+	return err
+}
+
+func validatePrefixPalette(value interface{}) error {
+	palette, err := cast.ToStringSliceE(value)
+	if err != nil {
+		return err
+	}
+	for _, style := range palette {
+		if err := validateStyle(style); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validatePrefixColors(value interface{}) error {
+	colors, err := cast.ToStringMapStringE(value)
+	if err != nil {
+		return err
+	}
+	for prefix, style := range colors {
+		if err := validateStyle(style); err != nil {
+			return fmt.Errorf("invalid color for prefix %q. %s", prefix, err)
+		}
+	}
+	return nil
+}
+
+func validateFilename(filename string) error {
+	if filename == "" {
+		return fmt.Errorf("empty filename")
+	}
+	dir := filepath.Dir(filename)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	return nil
+}
+
+func validateSize(value interface{}) error {
+	size, err := cast.ToIntE(value)
+	if err != nil {
+		return err
+	}
+	if size < 0 {
+		return fmt.Errorf("it cannot be negative")
+	}
+	return nil
+}
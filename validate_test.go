@@ -0,0 +1,67 @@
+package log_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/johandry/log"
+	"github.com/spf13/viper"
+)
+
+func TestValidate(t *testing.T) {
+	v := viper.New()
+	v.Set(log.LevelKey, "verbose")
+	v.Set(log.OutputKey, "stderr")
+	v.Set(log.FormatKey, "xml")
+	v.Set(log.ForceColorsKey, "maybe")
+	v.Set(log.MaxSizeKey, -1)
+
+	err := log.Validate(v)
+	var errs log.ConfigErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected ConfigErrors, but got %v", err)
+	}
+
+	expectedKeys := []string{log.ForceColorsKey, log.FormatKey, log.LevelKey, log.MaxSizeKey, log.OutputKey}
+	if !reflect.DeepEqual(errs.Keys(), expectedKeys) {
+		t.Errorf("Expected invalid keys %v, but got %v", expectedKeys, errs.Keys())
+	}
+
+	if l, err := log.NewWithError(v); err == nil || l != nil {
+		t.Errorf("Expected an error creating the logger, but got %v", err)
+	}
+
+	var b bytes.Buffer
+	v = viper.New()
+	v.Set(log.LevelKey, "debug")
+	v.Set(log.OutputKey, &b)
+	if err := log.Validate(v); err != nil {
+		t.Errorf("Expected no error, but got %v", err)
+	}
+}
+
+func TestNewWithErrorFilename(t *testing.T) {
+	v := viper.New()
+	v.Set(log.FilenameKey, filepath.Join(t.TempDir(), "missing", "test.log"))
+
+	_, err := log.NewWithError(v)
+	var configErr *log.ConfigError
+	if !errors.As(err, &configErr) || configErr.Key != log.FilenameKey {
+		t.Errorf("Expected an error for '%s', but got %v", log.FilenameKey, err)
+	}
+}
+
+func TestNewInvalidOutput(t *testing.T) {
+	v := viper.New()
+	v.Set(log.OutputKey, "stderr")
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("Expected no panic with an invalid output, but got %v", r)
+		}
+	}()
+	log.New(v)
+}
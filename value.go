@@ -0,0 +1,220 @@
+package log
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// defValueDepth is the nesting level of the values printed by the JSON and
+// expanded formats when MaxValueDepth is not set
+const defValueDepth = 10
+
+// keyValue is a field printed by the text formatter
+type keyValue struct {
+	key   string
+	value interface{}
+}
+
+// expandField returns the field, or its nested values with dotted keys if the
+// values are expanded
+func (f *TextFormatter) expandField(key string, value interface{}) []keyValue {
+	if f.ValueFormat != ValueExpand {
+		return []keyValue{{key, value}}
+	}
+	return flatten(nil, key, f.normalize(value, 0))
+}
+
+// flatten appends the leaves of the normalized value with the keys of their
+// parents separated by dots
+func flatten(fields []keyValue, key string, value interface{}) []keyValue {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 {
+			return append(fields, keyValue{key, rawText("{}")})
+		}
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fields = flatten(fields, key+"."+k, value[k])
+		}
+		return fields
+	case []interface{}:
+		if len(value) == 0 {
+			return append(fields, keyValue{key, rawText("[]")})
+		}
+		for i, v := range value {
+			fields = flatten(fields, key+"."+strconv.Itoa(i), v)
+		}
+		return fields
+	default:
+		return append(fields, keyValue{key, value})
+	}
+}
+
+// rawText is a value printed as it is, without quotes
+type rawText string
+
+// normalize converts the value to maps, slices and basic types, replacing the
+// values with a text representation by the text and the values deeper than the
+// maximum depth by an ellipsis
+func (f *TextFormatter) normalize(value interface{}, depth int) interface{} {
+	if text, ok := f.textOf(value); ok {
+		return text
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return f.normalize(v.Elem().Interface(), depth)
+	case reflect.Map, reflect.Struct, reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+		if (v.Kind() == reflect.Map || v.Kind() == reflect.Slice) && v.IsNil() {
+			return nil
+		}
+		if depth >= f.maxValueDepth() {
+			return rawText(ellipsis)
+		}
+	default:
+		return value
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			m[fmt.Sprint(f.normalize(k.Interface(), depth))] = f.normalize(v.MapIndex(k).Interface(), depth+1)
+		}
+		return m
+	case reflect.Struct:
+		m := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			if name, ok := fieldName(v.Type().Field(i)); ok {
+				m[name] = f.normalize(v.Field(i).Interface(), depth+1)
+			}
+		}
+		return m
+	default:
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = f.normalize(v.Index(i).Interface(), depth+1)
+		}
+		return s
+	}
+}
+
+// fieldName returns the name of an exported struct field, or its name in the
+// json tag
+func fieldName(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" {
+		return "", false
+	}
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return field.Name, true
+	default:
+		return name, true
+	}
+}
+
+func (f *TextFormatter) maxValueDepth() int {
+	if f.MaxValueDepth > 0 {
+		return f.MaxValueDepth
+	}
+	return defValueDepth
+}
+
+// textOf returns the text of the values printed as text: times, durations,
+// errors, fmt.Stringer and encoding.TextMarshaler
+func (f *TextFormatter) textOf(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case time.Time:
+		return f.formatTime(value, f.timestampFormat()), true
+	case time.Duration:
+		return value.String(), true
+	}
+
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr && v.IsNil() {
+		return "", false
+	}
+	switch value := value.(type) {
+	case error:
+		return value.Error(), true
+	case fmt.Stringer:
+		return value.String(), true
+	case encoding.TextMarshaler:
+		text, err := value.MarshalText()
+		if err != nil {
+			return "", false
+		}
+		return string(text), true
+	}
+	return "", false
+}
+
+// valueText returns the text of the value and if it could be quoted
+func (f *TextFormatter) valueText(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case rawText:
+		return string(value), false
+	}
+	if text, ok := f.textOf(value); ok {
+		return text, true
+	}
+
+	switch f.ValueFormat {
+	case ValueJSON:
+		var b bytes.Buffer
+		encoder := json.NewEncoder(&b)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(f.normalize(value, 0)); err == nil {
+			return strings.TrimSuffix(b.String(), "\n"), false
+		}
+		return fmt.Sprintf("%+v", value), false
+	case ValueVerbose:
+		return fmt.Sprintf("%+v", value), false
+	default:
+		return fmt.Sprint(value), false
+	}
+}
+
+// truncateValue shortens the text of a value longer than MaxValueLength,
+// ending it with an ellipsis
+func (f *TextFormatter) truncateValue(text string) string {
+	if f.MaxValueLength <= 0 || utf8.RuneCountInString(text) <= f.MaxValueLength {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:f.MaxValueLength-1]) + ellipsis
+}
+
+func validateValueFormat(format string) error {
+	switch format {
+	case "", ValueDefault, ValueJSON, ValueVerbose, ValueExpand:
+		return nil
+	default:
+		return fmt.Errorf("unknown value format %q, it should be %q, %q, %q or %q", format, ValueDefault, ValueJSON, ValueVerbose, ValueExpand)
+	}
+}
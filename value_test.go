@@ -0,0 +1,111 @@
+package log_test
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/johandry/log"
+	"github.com/spf13/viper"
+)
+
+type server struct {
+	Name    string
+	Port    int `json:"port"`
+	Tags    []string
+	Limits  map[string]int
+	Timeout time.Duration
+	Parent  *server
+	secret  string
+}
+
+func TestValueFormat(t *testing.T) {
+	s := server{
+		Name:    "api",
+		Port:    8080,
+		Tags:    []string{"a", "b c"},
+		Limits:  map[string]int{"rps": 10},
+		Timeout: 1500 * time.Millisecond,
+		secret:  "hidden",
+	}
+
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{log.ValueDefault, "INFO  Hello server={api 8080 [a b c] map[rps:10] 1.5s <nil> hidden}\n"},
+		{log.ValueVerbose, "INFO  Hello server={Name:api Port:8080 Tags:[a b c] Limits:map[rps:10] Timeout:1.5s Parent:<nil> secret:hidden}\n"},
+		{log.ValueJSON, `INFO  Hello server={"Limits":{"rps":10},"Name":"api","Parent":null,"Tags":["a","b c"],"Timeout":"1.5s","port":8080}` + "\n"},
+		{log.ValueExpand, `INFO  Hello server.Limits.rps=10 server.Name=api server.Parent=<nil> server.Tags.0=a server.Tags.1="b c" server.Timeout=1.5s server.port=8080` + "\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			var b bytes.Buffer
+			v := viper.New()
+			v.Set(log.DisableColorsKey, true)
+			v.Set(log.DisableTimestampKey, true)
+			v.Set(log.ValueFormatKey, tc.format)
+			l := newLogger(&b, v)
+
+			l.WithField("server", s).Info("Hello")
+			if actual := b.String(); actual != tc.expected {
+				t.Errorf("Expected %q, but got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestValueLimits(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.ValueFormatKey, log.ValueJSON)
+	v.Set(log.MaxValueDepthKey, 2)
+	v.Set(log.MaxValueLengthKey, 16)
+	l := newLogger(&b, v)
+
+	nested := map[string]interface{}{"a": map[string]interface{}{"b": map[string]int{"c": 1}}}
+	l.WithField("nested", nested).WithField("text", "a very long message").Info("Hello")
+	expectedLogMessage := `INFO  Hello nested={"a":{"b":"…"}} text="a very long mes…"` + "\n"
+	actualLogMessage := b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+
+	v.Set(log.MaxValueLengthKey, -1)
+	if err := log.Validate(v); err == nil {
+		t.Errorf("Expected an error for a negative %s", log.MaxValueLengthKey)
+	}
+	v.Set(log.MaxValueLengthKey, 0)
+	v.Set(log.ValueFormatKey, "yaml")
+	if err := log.Validate(v); err == nil {
+		t.Errorf("Expected an error for an invalid %s", log.ValueFormatKey)
+	}
+}
+
+func TestTextValues(t *testing.T) {
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.TimestampFormatKey, "2006-01-02T15:04:05")
+	v.Set(log.TimestampModeKey, log.TimestampUTC)
+	v.Set(log.ValueFormatKey, log.ValueJSON)
+	l := newLogger(&b, v)
+
+	ts := time.Date(2020, 5, 17, 20, 30, 15, 0, time.FixedZone("CEST", 2*60*60))
+	l.WithField("ip", net.ParseIP("10.0.0.1")).
+		WithField("at", ts).
+		WithField("hosts", []net.IP{net.ParseIP("10.0.0.2")}).
+		Info("Hello")
+	expectedLogMessage := `INFO  Hello at="2020-05-17T18:30:15" hosts=["10.0.0.2"] ip=10.0.0.1` + "\n"
+	actualLogMessage := b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected %q, but got %q", expectedLogMessage, actualLogMessage)
+	}
+}
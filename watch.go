@@ -0,0 +1,226 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// formatterKeys are the viper variables used to create the formatter
+var formatterKeys = []string{
+	FormatKey,
+	ForceColorsKey,
+	DisableColorsKey,
+	DisableTimestampKey,
+	ShortTimestampKey,
+	TimestampFormatKey,
+	AbbreviatePrefixKey,
+	MultilineKey,
+	ThemeKey,
+	ColorsKey,
+	ColorLevelKey,
+	HashPrefixColorsKey,
+	PrefixPaletteKey,
+	PrefixColorsKey,
+	PrefixWidthKey,
+	AutoPrefixWidthKey,
+	RightAlignLevelKey,
+	FieldsColumnKey,
+	TimestampModeKey,
+	ValueFormatKey,
+	MaxValueDepthKey,
+	MaxValueLengthKey,
+}
+
+// outputKeys are the viper variables used to create the log file
+var outputKeys = []string{
+	FilenameKey,
+	MaxSizeKey,
+	MaxAgeKey,
+	MaxBackupsKey,
+	DailyKey,
+	CompressKey,
+}
+
+// WatchFunc is called every time the configuration is reloaded with the viper
+// variables applied to the logger and the ones that failed validation. The
+// failed variables keep the previous value.
+type WatchFunc func(changed []string, failed map[string]error)
+
+// Watch reloads the logger settings every time the viper configuration file
+// changes, calling fn (if not nil) with the result. The level, formatter,
+// prefix, caller report, stack trace level and log file are applied live.
+//
+// Viper keeps only one OnConfigChange handler, so this replaces any handler
+// previously set in v.
+func (logger *Logger) Watch(v *viper.Viper, fn WatchFunc) {
+	logger.reloadMu.Lock()
+	logger.settings = settingsOf(v)
+	logger.reloadMu.Unlock()
+
+	v.OnConfigChange(func(fsnotify.Event) {
+		changed, failed := logger.Reload(v)
+		if fn != nil {
+			fn(changed, failed)
+		}
+	})
+	v.WatchConfig()
+}
+
+// Reload applies to the logger the settings in the viper instance that changed
+// since the last reload. It returns the viper variables applied and the ones
+// that failed validation.
+func (logger *Logger) Reload(v *viper.Viper) (changed []string, failed map[string]error) {
+	logger.reloadMu.Lock()
+	defer logger.reloadMu.Unlock()
+
+	failed = map[string]error{}
+	current := settingsOf(v)
+	modified := func(keys ...string) bool {
+		for _, k := range keys {
+			if old, ok := logger.settings[k]; !ok || old != current[k] {
+				return true
+			}
+		}
+		return false
+	}
+	applied := func(keys ...string) {
+		for _, k := range keys {
+			if old, ok := logger.settings[k]; !ok || old != current[k] {
+				changed = append(changed, k)
+			}
+		}
+	}
+	if logger.settings == nil {
+		logger.settings = map[string]string{}
+	}
+
+	if modified(LevelKey) && v.IsSet(LevelKey) {
+		level, err := ParsePrefixLevel(v.GetString(LevelKey))
+		if err != nil {
+			failed[LevelKey] = err
+			current[LevelKey] = logger.settings[LevelKey]
+		} else {
+			logger.SetLevel(level)
+			applied(LevelKey)
+		}
+	}
+
+	if modified(LevelsKey) {
+		if err := validateLevels(v.Get(LevelsKey)); err != nil && v.IsSet(LevelsKey) {
+			failed[LevelsKey] = err
+			current[LevelsKey] = logger.settings[LevelsKey]
+		} else {
+			logger.levelRules().clearAll()
+			for prefix, name := range v.GetStringMapString(LevelsKey) {
+				level, _ := ParsePrefixLevel(name)
+				logger.levelRules().set(prefix, level)
+			}
+			logger.applyLevels()
+			applied(LevelsKey)
+		}
+	}
+
+	if modified(formatterKeys...) {
+		key, err := FormatKey, validateFormat(v.GetString(FormatKey))
+		if err == nil {
+			key, err = MultilineKey, validateMultiline(v.GetString(MultilineKey))
+		}
+		if err == nil {
+			key, err = TimestampModeKey, validateTimestampMode(v.GetString(TimestampModeKey))
+		}
+		if err == nil {
+			key, err = ValueFormatKey, validateValueFormat(v.GetString(ValueFormatKey))
+		}
+		if err == nil {
+			key, err = ThemeKey, validateTheme(v.GetString(ThemeKey), v.Get(ColorsKey))
+		}
+		if err == nil {
+			key = ColorLevelKey
+			_, err = ParseColorLevel(v.GetString(ColorLevelKey))
+		}
+		if err == nil && v.IsSet(PrefixPaletteKey) {
+			key, err = PrefixPaletteKey, validatePrefixPalette(v.Get(PrefixPaletteKey))
+		}
+		if err == nil && v.IsSet(PrefixColorsKey) {
+			key, err = PrefixColorsKey, validatePrefixColors(v.Get(PrefixColorsKey))
+		}
+		if err != nil {
+			failed[key] = err
+			for _, k := range formatterKeys {
+				current[k] = logger.settings[k]
+			}
+		} else {
+			logger.SetFormatter(ConfigFromViper(v).formatter())
+			applied(formatterKeys...)
+		}
+	}
+
+	if modified(CallerKey) {
+		logger.SetReportCaller(v.GetBool(CallerKey))
+		applied(CallerKey)
+	}
+
+	if modified(StackTraceLevelKey) {
+		level, err := ParsePrefixLevel(v.GetString(StackTraceLevelKey))
+		switch {
+		case !v.IsSet(StackTraceLevelKey):
+			logger.SetStackTraceLevel(OffLevel)
+			applied(StackTraceLevelKey)
+		case err != nil:
+			failed[StackTraceLevelKey] = err
+			current[StackTraceLevelKey] = logger.settings[StackTraceLevelKey]
+		default:
+			logger.SetStackTraceLevel(level)
+			applied(StackTraceLevelKey)
+		}
+	}
+
+	if modified(PrefixField) {
+		logger.SetPrefix(v.GetString(PrefixField))
+		applied(PrefixField)
+	}
+
+	if modified(outputKeys...) && !v.IsSet(OutputKey) && v.IsSet(FilenameKey) {
+		// the new rotation settings apply to every logger sharing the file
+		out, err := updateRotatingFile(ConfigFromViper(v).rotatingFileSettings())
+		if err != nil {
+			failed[FilenameKey] = err
+			for _, k := range outputKeys {
+				current[k] = logger.settings[k]
+			}
+		} else {
+			previous, _ := logger.output().(*RotatingFile)
+			logger.SetOutput(out)
+			if v.GetBool(ReopenKey) {
+				out.reopenOnSignal()
+			}
+			// the previous log file opened from the configuration is closed, so
+			// it can be opened again with other settings
+			if previous != nil && previous != out && previous.shared() {
+				if err := previous.Close(); err != nil {
+					logger.Errorf("Cannot close log file %s. %s", previous.Filename, err)
+				}
+			}
+			applied(outputKeys...)
+		}
+	}
+
+	logger.settings = current
+	sort.Strings(changed)
+
+	return changed, failed
+}
+
+// settingsOf returns the values of the viper variables applied by Reload
+func settingsOf(v *viper.Viper) map[string]string {
+	settings := map[string]string{}
+	keys := append([]string{LevelKey, LevelsKey, PrefixField, CallerKey, StackTraceLevelKey}, formatterKeys...)
+	keys = append(keys, outputKeys...)
+	for _, k := range keys {
+		settings[k] = fmt.Sprint(v.Get(k))
+	}
+	return settings
+}
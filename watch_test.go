@@ -0,0 +1,159 @@
+package log_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/johandry/log"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func TestReload(t *testing.T) {
+	var expectedLogMessage string
+	var actualLogMessage string
+
+	var b bytes.Buffer
+	v := viper.New()
+
+	v.Set(log.DisableColorsKey, true)
+	v.Set(log.DisableTimestampKey, true)
+	v.Set(log.LevelKey, "info")
+	l := newLogger(&b, v)
+	l.Reload(v)
+
+	l.Debugf("Debug")
+	if b.Len() != 0 {
+		t.Errorf("Expected no debug message, but got '%s'", b.String())
+	}
+
+	v.Set(log.LevelKey, "debug")
+	v.Set(log.FormatKey, log.LogfmtFormat)
+	v.Set(log.PrefixField, "test")
+	changed, failed := l.Reload(v)
+
+	expectedChanged := []string{log.FormatKey, log.LevelKey, log.PrefixField}
+	if !reflect.DeepEqual(changed, expectedChanged) {
+		t.Errorf("Expected changes %v, but got %v", expectedChanged, changed)
+	}
+	if len(failed) != 0 {
+		t.Errorf("Expected no failures, but got %v", failed)
+	}
+
+	l.Debugf("Debug")
+	expectedLogMessage = "level=debug prefix=test msg=Debug\n"
+	actualLogMessage = b.String()
+	if actualLogMessage != expectedLogMessage {
+		t.Errorf("Expected '%s', but got '%s'", expectedLogMessage, actualLogMessage)
+	}
+	b.Reset()
+
+	v.Set(log.LevelKey, "verbose")
+	changed, failed = l.Reload(v)
+	if len(changed) != 0 {
+		t.Errorf("Expected no changes, but got %v", changed)
+	}
+	if _, ok := failed[log.LevelKey]; !ok || len(failed) != 1 {
+		t.Errorf("Expected '%s' to fail, but got %v", log.LevelKey, failed)
+	}
+	if l.GetLevel() != logrus.DebugLevel {
+		t.Errorf("Expected the level to stay in debug, but got %s", l.GetLevel())
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte("log_level: info\n"), 0600); err != nil {
+		t.Fatalf("Cannot write config file. %s", err)
+	}
+
+	var b bytes.Buffer
+	v := viper.New()
+	v.SetConfigFile(configFile)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("Cannot read config file. %s", err)
+	}
+	l := newLogger(&b, v)
+
+	done := make(chan []string, 1)
+	l.Watch(v, func(changed []string, failed map[string]error) {
+		select {
+		case done <- changed:
+		default:
+		}
+	})
+
+	if err := os.WriteFile(configFile, []byte("log_level: debug\n"), 0600); err != nil {
+		t.Fatalf("Cannot write config file. %s", err)
+	}
+
+	select {
+	case changed := <-done:
+		if !reflect.DeepEqual(changed, []string{log.LevelKey}) {
+			t.Errorf("Expected changes %v, but got %v", []string{log.LevelKey}, changed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timeout waiting for the configuration to reload")
+	}
+	if l.GetLevel() != logrus.DebugLevel {
+		t.Errorf("Expected the level to be debug, but got %s", l.GetLevel())
+	}
+}
+
+func TestReloadRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+
+	v := viper.New()
+	v.Set(log.FilenameKey, filepath.Join(dir, "test.log"))
+	v.Set(log.MaxSizeKey, 100)
+	l := log.New(v)
+	other := log.New(v)
+	defer l.Out.(*log.RotatingFile).Close()
+	l.Reload(v)
+
+	v.Set(log.MaxSizeKey, 1)
+	changed, failed := l.Reload(v)
+	if !reflect.DeepEqual(changed, []string{log.MaxSizeKey}) || len(failed) != 0 {
+		t.Errorf("Expected %s to change, but got %v and failures %v", log.MaxSizeKey, changed, failed)
+	}
+	for _, logger := range []*log.Logger{l, other} {
+		if maxSize := logger.Out.(*log.RotatingFile).MaxSize; maxSize != 1 {
+			t.Errorf("Expected the log file max size to be 1, but got %d", maxSize)
+		}
+	}
+}
+
+func TestReloadFilename(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.log")
+
+	v := viper.New()
+	v.Set(log.FilenameKey, filename)
+	v.Set(log.MaxSizeKey, 100)
+	l := log.New(v)
+	l.Reload(v)
+	previous := l.Out.(*log.RotatingFile)
+
+	v.Set(log.FilenameKey, filepath.Join(dir, "new.log"))
+	changed, failed := l.Reload(v)
+	if !reflect.DeepEqual(changed, []string{log.FilenameKey}) || len(failed) != 0 {
+		t.Errorf("Expected %s to change, but got %v and failures %v", log.FilenameKey, changed, failed)
+	}
+	defer l.Out.(*log.RotatingFile).Close()
+
+	// the previous log file is closed, so it can be opened with other settings
+	other := viper.New()
+	other.Set(log.FilenameKey, filename)
+	other.Set(log.MaxSizeKey, 1)
+	o := log.New(other)
+	out, ok := o.Out.(*log.RotatingFile)
+	if !ok || out == previous || out.MaxSize != 1 {
+		t.Fatalf("Expected a new log file with max size 1, but got %T %v", o.Out, o.Out)
+	}
+	out.Close()
+}